@@ -0,0 +1,157 @@
+package pointerstructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Children returns the parts that address the children of the value p
+// points to in v, which is useful for autocompletion. These are the keys of
+// a map sorted like Sort, the indexes of a slice followed by "-" for
+// appending, the indexes of an array, or the names of the fields of a
+// struct using the same tag rules as Get. Non-string map keys are
+// formatted with fmt.
+//
+// The value must be a map, slice, array or struct, otherwise an error
+// wrapping ErrInvalidKind is returned. A nil value has no children.
+func (p *Pointer) Children(v interface{}) ([]string, error) {
+	return p.children(v, true)
+}
+
+// childParts is Children without the "-" part for slices.
+func (p *Pointer) childParts(v interface{}) ([]string, error) {
+	return p.children(v, false)
+}
+
+func (p *Pointer) children(v interface{}, appendable bool) ([]string, error) {
+	value, _, err := p.get(v)
+	if err != nil {
+		return nil, err
+	}
+
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Map:
+		parts := make([]string, 0, value.Len())
+		for _, k := range value.MapKeys() {
+			if k.Kind() == reflect.String {
+				parts = append(parts, k.String())
+			} else {
+				parts = append(parts, fmt.Sprint(k.Interface()))
+			}
+		}
+
+		sort.Slice(parts, func(i, j int) bool {
+			return compareParts(parts[i], parts[j]) < 0
+		})
+		return parts, nil
+
+	case reflect.Slice, reflect.Array:
+		parts := indexParts(value.Len())
+		if appendable && value.Kind() == reflect.Slice {
+			parts = append(parts, "-")
+		}
+		return parts, nil
+
+	case reflect.Struct:
+		return p.fieldParts(value.Type())
+
+	default:
+		return nil, fmt.Errorf("%s: %w: %s", p, ErrInvalidKind, value.Kind())
+	}
+}
+
+// ChildrenOfType is like Children, but uses only the type t rather than a
+// value of it. The pointer is followed through the type: map keys and
+// slice indexes may be anything, and struct fields must exist.
+//
+// Since the keys of a map and the length of a slice aren't known from the
+// type, a map has no children and a slice only "-". The children of an
+// interface type aren't known either, so it has no children, and a pointer
+// through one returns an error wrapping ErrInvalidKind.
+func (p *Pointer) ChildrenOfType(t reflect.Type) ([]string, error) {
+	for i, part := range p.Parts {
+		t = indirectType(t)
+
+		switch t.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+
+		case reflect.Struct:
+			fields, err := cachedStructFields(t, p.Config.tagName())
+			if err != nil {
+				return nil, err
+			}
+
+			f, err := p.structField(fields, part)
+			if err != nil {
+				return nil, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
+
+			t = f.typ
+
+		default:
+			return nil, fmt.Errorf(
+				"%s: at part %d, %w: %s", p, i, ErrInvalidKind, t.Kind())
+		}
+	}
+
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		return nil, nil
+
+	case reflect.Slice:
+		return []string{"-"}, nil
+
+	case reflect.Array:
+		return indexParts(t.Len()), nil
+
+	case reflect.Struct:
+		return p.fieldParts(t)
+
+	default:
+		return nil, fmt.Errorf("%s: %w: %s", p, ErrInvalidKind, t.Kind())
+	}
+}
+
+// fieldParts returns the names of the fields of the struct type t.
+func (p *Pointer) fieldParts(t reflect.Type) ([]string, error) {
+	fields, err := cachedStructFields(t, p.Config.tagName())
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(fields.list))
+	for i, f := range fields.list {
+		parts[i] = f.name
+	}
+
+	return parts, nil
+}
+
+// indexParts returns the parts for the indexes of a slice of length n.
+func indexParts(n int) []string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprint(i)
+	}
+
+	return parts
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
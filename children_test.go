@@ -0,0 +1,95 @@
+package pointerstructure
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type childrenTest struct {
+	Name    string `pointer:"name"`
+	Ignored string `pointer:"-"`
+	Ports   []int
+	Pair    [2]string
+	Labels  map[string]string
+	Nested  *childrenTest
+	Any     interface{}
+	private string
+}
+
+func TestPointerChildren(t *testing.T) {
+	doc := map[string]interface{}{
+		"b":      1,
+		"a":      2,
+		"list":   []interface{}{1, 2},
+		"struct": &childrenTest{Name: "x"},
+		"ints":   map[int]bool{10: true, 9: true},
+		"nil":    nil,
+		"str":    "s",
+		"embed": &struct {
+			testMetadata `pointer:"meta"`
+		}{},
+	}
+
+	cases := []struct {
+		Pointer string
+		Output  []string
+		Err     error
+	}{
+		{"", []string{"a", "b", "embed", "ints", "list", "nil", "str", "struct"}, nil},
+		{"/embed", []string{"Name", "Kind"}, nil},
+		{"/list", []string{"0", "1", "-"}, nil},
+		{"/struct", []string{"name", "Ports", "Pair", "Labels", "Nested", "Any"}, nil},
+		{"/struct/Pair", []string{"0", "1"}, nil},
+		{"/ints", []string{"9", "10"}, nil},
+		{"/nil", nil, nil},
+		{"/str", nil, ErrInvalidKind},
+		{"/missing", nil, ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			actual, err := MustParse(tc.Pointer).Children(doc)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected %v, got: %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
+
+func TestPointerChildrenOfType(t *testing.T) {
+	typ := reflect.TypeOf(&childrenTest{})
+
+	cases := []struct {
+		Pointer string
+		Output  []string
+		Err     error
+	}{
+		{"", []string{"name", "Ports", "Pair", "Labels", "Nested", "Any"}, nil},
+		{"/Ports", []string{"-"}, nil},
+		{"/Pair", []string{"0", "1"}, nil},
+		{"/Labels", nil, nil},
+		{"/Any", nil, nil},
+		{"/Nested/Nested/name", nil, ErrInvalidKind},
+		{"/Nested/Nested", []string{"name", "Ports", "Pair", "Labels", "Nested", "Any"}, nil},
+		{"/Any/x", nil, ErrInvalidKind},
+		{"/missing", nil, ErrNotFound},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			actual, err := MustParse(tc.Pointer).ChildrenOfType(typ)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected %v, got: %v", tc.Err, err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package pointerstructure
+
+import (
+	"reflect"
+	"strings"
+)
+
+// CloneHookFn is called by Clone for each value before it is copied. If the
+// returned value is valid, it is used as the copy of v rather than copying
+// v with reflection. Returning the zero reflect.Value copies v as usual.
+//
+// This is useful for types that can't be copied by copying their exported
+// fields, for example types with unexported state or that must be shared.
+type CloneHookFn func(v reflect.Value) (reflect.Value, error)
+
+// Clone returns a deep copy of v.
+//
+// Maps, slices, arrays, pointers, interfaces and the struct fields that a
+// pointer can address are copied recursively, following the same tag rules
+// as Get. This includes the structs embedded by unexported fields, since
+// their fields are promoted. Other unexported struct fields, fields ignored
+// with a "-" tag, channels and functions are shared with v. Cycles and
+// values referenced multiple times are preserved in the copy.
+func Clone(v interface{}) (interface{}, error) {
+	return CloneWithConfig(v, Config{})
+}
+
+// CloneWithConfig is like Clone, but calls the Config.CloneHook for
+// each value to allow customizing how values are copied, and uses the
+// Config.TagName for struct tags.
+func CloneWithConfig(v interface{}, c Config) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	cl := &cloner{
+		hook:    c.CloneHook,
+		tagName: c.tagName(),
+		visited: map[cloneKey]reflect.Value{},
+	}
+
+	result, err := cl.clone(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Interface(), nil
+}
+
+// cloneKey identifies a value that is referenced rather than stored inline,
+// so that it is copied only once.
+type cloneKey struct {
+	typ reflect.Type
+	ptr uintptr
+	len int
+}
+
+type cloner struct {
+	hook    CloneHookFn
+	tagName string
+	visited map[cloneKey]reflect.Value
+}
+
+func (c *cloner) clone(v reflect.Value) (reflect.Value, error) {
+	if c.hook != nil {
+		result, err := c.hook(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if result.IsValid() {
+			return result, nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		elem, err := c.clone(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		result.Set(elem)
+		return result, nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		key := cloneKey{typ: v.Type(), ptr: v.Pointer()}
+		if result, ok := c.visited[key]; ok {
+			return result, nil
+		}
+
+		result := reflect.New(v.Type().Elem())
+		c.visited[key] = result
+
+		elem, err := c.clone(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result.Elem().Set(elem)
+		return result, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		key := cloneKey{typ: v.Type(), ptr: v.Pointer()}
+		if result, ok := c.visited[key]; ok {
+			return result, nil
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		c.visited[key] = result
+
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := c.clone(iter.Value())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			result.SetMapIndex(iter.Key(), elem)
+		}
+
+		return result, nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		key := cloneKey{typ: v.Type(), ptr: v.Pointer(), len: v.Len()}
+		if result, ok := c.visited[key]; ok {
+			return result, nil
+		}
+
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		c.visited[key] = result
+
+		for i := 0; i < v.Len(); i++ {
+			elem, err := c.clone(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			result.Index(i).Set(elem)
+		}
+
+		return result, nil
+
+	case reflect.Array:
+		result := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			elem, err := c.clone(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			result.Index(i).Set(elem)
+		}
+
+		return result, nil
+
+	case reflect.Struct:
+		// Copy the whole struct first so fields that aren't copied are
+		// shared, then replace the other fields with copies.
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+
+		if err := c.cloneFields(result, v); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return result, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// cloneFields replaces the fields of the addressable struct dst, a shallow
+// copy of src, with copies of them. Fields ignored with a "-" tag and
+// unexported fields are kept shared, but the exported fields of unexported
+// embedded structs are copied since they are promoted like Get does.
+func (c *cloner) cloneFields(dst, src reflect.Value) error {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get(c.tagName)
+		if idx := strings.Index(tag, ","); idx != -1 {
+			tag = tag[:idx]
+		}
+		if tag == "-" {
+			continue
+		}
+
+		f := dst.Field(i)
+		if sf.Anonymous && sf.PkgPath != "" {
+			// The fields of an unexported embedded struct are promoted, so
+			// they are copied. Those of an embedded struct value can be set
+			// directly, while an embedded pointer is replaced with a copy.
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+				if err := c.cloneFields(f, src.Field(i)); err != nil {
+					return err
+				}
+
+			case sf.Type.Kind() == reflect.Ptr && sf.Type.Elem().Kind() == reflect.Struct:
+				// dst is a copy of src, so f holds the same pointer
+				f = unexportedEmbed(f)
+				elem, err := c.clone(f)
+				if err != nil {
+					return err
+				}
+
+				f.Set(elem)
+			}
+
+			continue
+		}
+
+		if !f.CanSet() {
+			continue
+		}
+
+		elem, err := c.clone(src.Field(i))
+		if err != nil {
+			return err
+		}
+
+		f.Set(elem)
+	}
+
+	return nil
+}
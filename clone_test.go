@@ -0,0 +1,192 @@
+package pointerstructure
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClone(t *testing.T) {
+	type inner struct {
+		Values []int
+	}
+	type outer struct {
+		Name    string
+		Inner   *inner
+		Map     map[string]interface{}
+		Array   [2]*inner
+		private *inner
+	}
+
+	shared := &inner{Values: []int{1}}
+	input := &outer{
+		Name:    "foo",
+		Inner:   &inner{Values: []int{1, 2}},
+		Map:     map[string]interface{}{"a": []interface{}{"b"}},
+		Array:   [2]*inner{shared, shared},
+		private: shared,
+	}
+
+	raw, err := Clone(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := raw.(*outer)
+	if !reflect.DeepEqual(result, input) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	// Modifying the copy must not modify the input
+	result.Inner.Values[0] = 42
+	result.Map["a"].([]interface{})[0] = "c"
+	result.Array[0].Values[0] = 42
+	if input.Inner.Values[0] != 1 || input.Map["a"].([]interface{})[0] != "b" || shared.Values[0] != 1 {
+		t.Fatalf("input modified: %#v", input)
+	}
+
+	// Values referenced multiple times are copied once
+	if result.Array[0] != result.Array[1] {
+		t.Fatal("shared pointer copied twice")
+	}
+
+	// Unexported fields are shared
+	if result.private != shared {
+		t.Fatal("unexported field copied")
+	}
+}
+
+func TestClone_structTags(t *testing.T) {
+	type embedded struct {
+		Values []int
+	}
+	type outer struct {
+		embedded
+		Ignored []int `pointer:"-"`
+		Renamed []int `pointer:"renamed"`
+	}
+
+	input := outer{
+		embedded: embedded{Values: []int{1}},
+		Ignored:  []int{2},
+		Renamed:  []int{3},
+	}
+
+	raw, err := Clone(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := raw.(outer)
+	if !reflect.DeepEqual(result, input) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	result.Values[0] = 42
+	result.Renamed[0] = 42
+	if input.Values[0] != 1 || input.Renamed[0] != 3 {
+		t.Fatalf("input modified: %#v", input)
+	}
+
+	// Ignored fields are shared
+	result.Ignored[0] = 42
+	if input.Ignored[0] != 42 {
+		t.Fatal("ignored field copied")
+	}
+}
+
+func TestClone_unexportedEmbeddedPointer(t *testing.T) {
+	type embedded struct {
+		Values []int
+	}
+	type outer struct {
+		*embedded
+	}
+
+	input := outer{&embedded{Values: []int{1}}}
+	raw, err := Clone(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := raw.(outer)
+	if result.embedded == input.embedded {
+		t.Fatal("embedded pointer shared")
+	}
+
+	result.Values[0] = 42
+	if input.Values[0] != 1 {
+		t.Fatalf("input modified: %#v", input.Values)
+	}
+}
+
+func TestClone_cycle(t *testing.T) {
+	type node struct {
+		Next *node
+	}
+
+	n := &node{}
+	n.Next = n
+
+	raw, err := Clone(n)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := raw.(*node)
+	if result == n || result.Next != result {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestClone_nil(t *testing.T) {
+	result, err := Clone(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result != nil {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	var m map[string]interface{}
+	result, err = Clone(m)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result.(map[string]interface{}) != nil {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestCloneWithConfig_hook(t *testing.T) {
+	now := time.Now()
+	input := map[string]interface{}{"time": now, "n": 1}
+
+	timeType := reflect.TypeOf(now)
+	raw, err := CloneWithConfig(input, Config{
+		CloneHook: func(v reflect.Value) (reflect.Value, error) {
+			if v.Type() == timeType {
+				// time.Time has unexported state, so share it as-is
+				return v, nil
+			}
+			return reflect.Value{}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(raw, input) {
+		t.Fatalf("bad: %#v", raw)
+	}
+
+	hookErr := errors.New("nope")
+	_, err = CloneWithConfig(input, Config{
+		CloneHook: func(v reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, hookErr
+		},
+	})
+	if !errors.Is(err, hookErr) {
+		t.Fatalf("expected hook error, got: %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/mitchellh/pointerstructure"
+)
+
+// env is the environment a command runs in.
+type env struct {
+	name   string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// flags returns a flag set for the command that reports errors to stderr.
+func (e *env) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet(e.name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// parse parses the flags of the command and checks the number of
+// remaining arguments is within [min, max].
+func (e *env) parse(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+
+	if fs.NArg() < min || fs.NArg() > max {
+		return nil, errUsage
+	}
+
+	return fs.Args(), nil
+}
+
+// read reads the document from the file, or stdin if the file is "-" or
+// empty.
+func (e *env) read(file string) ([]byte, error) {
+	if file == "" || file == "-" {
+		return ioutil.ReadAll(e.stdin)
+	}
+
+	return ioutil.ReadFile(file)
+}
+
+// write writes the modified document to the file if inPlace is set, or to
+// stdout otherwise.
+func (e *env) write(file string, inPlace bool, doc []byte) error {
+	if !inPlace {
+		return e.print(doc)
+	}
+
+	if file == "" || file == "-" {
+		return fmt.Errorf("-w requires a file")
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, withNewline(doc), info.Mode())
+}
+
+// print writes the document to stdout, ending with a newline.
+func (e *env) print(doc []byte) error {
+	_, err := e.stdout.Write(withNewline(doc))
+	return err
+}
+
+func withNewline(doc []byte) []byte {
+	if bytes.HasSuffix(doc, []byte("\n")) {
+		return doc
+	}
+
+	return append(doc, '\n')
+}
+
+// arg returns the argument at index i, or "" if there are fewer arguments.
+func arg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+
+	return ""
+}
+
+func cmdGet(e *env, args []string) error {
+	fs := e.flags()
+	raw := fs.Bool("raw", false, "print strings without quotes")
+	args, err := e.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	p, err := pointerstructure.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	doc, err := e.read(arg(args, 1))
+	if err != nil {
+		return err
+	}
+
+	value, err := pointerstructure.GetJSON(bytes.NewReader(doc), p)
+	if err != nil {
+		return err
+	}
+
+	if *raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err == nil {
+			_, err := fmt.Fprintln(e.stdout, s)
+			return err
+		}
+	}
+
+	return e.print(value)
+}
+
+func cmdSet(e *env, args []string) error {
+	fs := e.flags()
+	inPlace := fs.Bool("w", false, "write the result to the file")
+	str := fs.Bool("string", false, "set VALUE as a string rather than JSON")
+	args, err := e.parse(fs, args, 2, 3)
+	if err != nil {
+		return err
+	}
+
+	p, err := pointerstructure.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Values that aren't valid JSON are set as strings
+	var value interface{} = args[1]
+	if !*str && json.Valid([]byte(args[1])) {
+		value = json.RawMessage(args[1])
+	}
+
+	doc, err := e.read(arg(args, 2))
+	if err != nil {
+		return err
+	}
+
+	doc, err = pointerstructure.SetJSON(doc, p, value)
+	if err != nil {
+		return err
+	}
+
+	return e.write(arg(args, 2), *inPlace, doc)
+}
+
+func cmdDelete(e *env, args []string) error {
+	fs := e.flags()
+	inPlace := fs.Bool("w", false, "write the result to the file")
+	args, err := e.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	p, err := pointerstructure.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	doc, err := e.read(arg(args, 1))
+	if err != nil {
+		return err
+	}
+
+	// Deleting a missing value is an error, like the "remove" operation
+	if _, err := pointerstructure.GetJSON(bytes.NewReader(doc), p); err != nil {
+		return err
+	}
+
+	doc, err = pointerstructure.DeleteJSON(doc, p)
+	if err != nil {
+		return err
+	}
+
+	return e.write(arg(args, 1), *inPlace, doc)
+}
+
+func cmdPatch(e *env, args []string) error {
+	fs := e.flags()
+	inPlace := fs.Bool("w", false, "write the result to the file")
+	args, err := e.parse(fs, args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	patchDoc, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var patch pointerstructure.Patch
+	if err := json.Unmarshal(patchDoc, &patch); err != nil {
+		return fmt.Errorf("reading patch %s: %w", args[0], err)
+	}
+
+	doc, err := e.read(arg(args, 1))
+	if err != nil {
+		return err
+	}
+
+	doc, err = patch.ApplyJSON(doc)
+	if err != nil {
+		return err
+	}
+
+	return e.write(arg(args, 1), *inPlace, doc)
+}
+
+func cmdDiff(e *env, args []string) error {
+	args, err := e.parse(e.flags(), args, 2, 2)
+	if err != nil {
+		return err
+	}
+
+	var docs [2]interface{}
+	for i, file := range args {
+		doc, err := e.read(file)
+		if err != nil {
+			return err
+		}
+
+		if err := decodeJSON(doc, &docs[i]); err != nil {
+			return fmt.Errorf("reading %s: %w", file, err)
+		}
+	}
+
+	patch := diff(nil, docs[0], docs[1])
+	if patch == nil {
+		patch = pointerstructure.Patch{}
+	}
+
+	result, err := encodeJSON(patch)
+	if err != nil {
+		return err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, result, "", "  "); err != nil {
+		return err
+	}
+
+	return e.print(indented.Bytes())
+}
+
+func cmdFlatten(e *env, args []string) error {
+	args, err := e.parse(e.flags(), args, 0, 1)
+	if err != nil {
+		return err
+	}
+
+	doc, err := e.read(arg(args, 0))
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	return flatten(dec, nil, e.stdout)
+}
+
+func cmdList(e *env, args []string) error {
+	args, err := e.parse(e.flags(), args, 1, 2)
+	if err != nil {
+		return err
+	}
+
+	p, err := pointerstructure.Parse(args[0])
+	if err != nil {
+		return err
+	}
+
+	doc, err := e.read(arg(args, 1))
+	if err != nil {
+		return err
+	}
+
+	value, err := pointerstructure.GetJSON(bytes.NewReader(doc), p)
+	if err != nil {
+		return err
+	}
+
+	return list(value, p.Parts, e.stdout)
+}
+
+// encodeJSON encodes v the same way as SetJSON, which doesn't escape HTML
+// characters or add a trailing newline.
+func encodeJSON(v interface{}) ([]byte, error) {
+	return pointerstructure.SetJSON(nil, &pointerstructure.Pointer{}, v)
+}
+
+// decodeJSON decodes the JSON document doc into v, keeping numbers as
+// json.Number so they are compared and printed exactly.
+func decodeJSON(doc []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
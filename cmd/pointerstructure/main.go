@@ -0,0 +1,112 @@
+// Command pointerstructure queries and edits JSON documents by JSON Pointer
+// (RFC 6901) using the same semantics as the pointerstructure library.
+//
+// Edits only change the bytes of the modified values, so the order of keys
+// and formatting of the rest of the document are preserved.
+//
+// The exit code is 3 if a key isn't found, 4 if an index is out of range,
+// 5 if a value can't be converted, 6 if a pointer can't be parsed, 2 for
+// invalid usage and 1 for any other error.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mitchellh/pointerstructure"
+)
+
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitUsage      = 2
+	exitNotFound   = 3
+	exitOutOfRange = 4
+	exitConvert    = 5
+	exitParse      = 6
+)
+
+const usage = `Usage: pointerstructure <command> [options] [arguments]
+
+Commands:
+  get [-raw] POINTER [FILE]         print the value at POINTER
+  set [-w] [-string] POINTER VALUE [FILE]
+                                    set the value at POINTER to the JSON VALUE
+  delete [-w] POINTER [FILE]        delete the value at POINTER
+  patch [-w] PATCH [FILE]           apply the JSON Patch (RFC 6902) in PATCH
+  diff FILE1 FILE2                  print a JSON Patch from FILE1 to FILE2
+  flatten [FILE]                    print the pointer and value of each leaf
+  list POINTER [FILE]               print the pointers of the children of POINTER
+
+The root of the document is the empty POINTER "".
+The document is read from FILE, or from stdin if FILE is "-" or missing.
+Modified documents are written to stdout, or back to FILE with -w.
+`
+
+// errUsage is returned by commands for invalid arguments.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command with the given arguments and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	commands := map[string]func(*env, []string) error{
+		"get":     cmdGet,
+		"set":     cmdSet,
+		"delete":  cmdDelete,
+		"patch":   cmdPatch,
+		"diff":    cmdDiff,
+		"flatten": cmdFlatten,
+		"list":    cmdList,
+	}
+
+	name := args[0]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "pointerstructure: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+
+	e := &env{name: name, stdin: stdin, stdout: stdout, stderr: stderr}
+	if err := cmd(e, args[1:]); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprint(stderr, usage)
+			return exitUsage
+		}
+
+		fmt.Fprintf(stderr, "pointerstructure %s: %s\n", name, err)
+		return exitCode(err)
+	}
+
+	return exitOK
+}
+
+// exitCode returns the exit code for the error returned by a command.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, pointerstructure.ErrParse):
+		return exitParse
+	case errors.Is(err, pointerstructure.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, pointerstructure.ErrOutOfRange):
+		return exitOutOfRange
+	case errors.Is(err, pointerstructure.ErrConvert):
+		return exitConvert
+	default:
+		return exitError
+	}
+}
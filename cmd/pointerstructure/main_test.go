@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDoc = `{"name":"web","ports":[80,443],"tls":{"cert":"a<b"},"empty":{}}`
+
+func TestRun(t *testing.T) {
+	cases := []struct {
+		Args   []string
+		Stdin  string
+		Stdout string
+		Code   int
+	}{
+		{[]string{"get", "/name"}, testDoc, "\"web\"\n", exitOK},
+		{[]string{"get", "-raw", "/tls/cert"}, testDoc, "a<b\n", exitOK},
+		{[]string{"get", ""}, `[1.50]`, "[1.50]\n", exitOK},
+		{[]string{"get", "/missing"}, testDoc, "", exitNotFound},
+		{[]string{"get", "/ports/2"}, testDoc, "", exitOutOfRange},
+		{[]string{"get", "/ports/x"}, testDoc, "", exitConvert},
+		{[]string{"get", "name"}, testDoc, "", exitParse},
+		{[]string{"get"}, testDoc, "", exitUsage},
+		{[]string{"frobnicate"}, testDoc, "", exitUsage},
+		{[]string{}, testDoc, "", exitUsage},
+
+		{
+			[]string{"set", "/ports/-", "8080"},
+			testDoc,
+			`{"name":"web","ports":[80,443,8080],"tls":{"cert":"a<b"},"empty":{}}` + "\n",
+			exitOK,
+		},
+		{
+			[]string{"set", "/tls/key", "not json"},
+			testDoc,
+			`{"name":"web","ports":[80,443],"tls":{"cert":"a<b","key":"not json"},"empty":{}}` + "\n",
+			exitOK,
+		},
+		{
+			[]string{"set", "-string", "/name", "true"},
+			testDoc,
+			`{"name":"true","ports":[80,443],"tls":{"cert":"a<b"},"empty":{}}` + "\n",
+			exitOK,
+		},
+		{[]string{"set", "/missing/key", "1"}, testDoc, "", exitNotFound},
+
+		{
+			[]string{"delete", "/ports/0"},
+			testDoc,
+			`{"name":"web","ports":[443],"tls":{"cert":"a<b"},"empty":{}}` + "\n",
+			exitOK,
+		},
+		{[]string{"delete", "/missing"}, testDoc, "", exitNotFound},
+
+		{
+			[]string{"flatten"},
+			testDoc,
+			"/name\t\"web\"\n/ports/0\t80\n/ports/1\t443\n/tls/cert\t\"a<b\"\n/empty\t{}\n",
+			exitOK,
+		},
+		{[]string{"list", ""}, testDoc, "/name\n/ports\n/tls\n/empty\n", exitOK},
+		{[]string{"list", "/ports"}, testDoc, "/ports/0\n/ports/1\n", exitOK},
+		{[]string{"list", "/name"}, testDoc, "", exitError},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, strings.Join(tc.Args, " ")), func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tc.Args, strings.NewReader(tc.Stdin), &stdout, &stderr)
+			if code != tc.Code {
+				t.Fatalf("bad exit code %d, stderr: %s", code, stderr.String())
+			}
+
+			if stdout.String() != tc.Stdout {
+				t.Fatalf("bad stdout: %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestRun_files(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pointerstructure")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		return path
+	}
+
+	doc := write("doc.json", "{\n  \"b\": 1,\n  \"a\": [1, 2]\n}\n")
+	other := write("other.json", `{"b": 2, "a": [1], "c": null}`)
+	patch := write("patch.json", `[
+		{"op": "test", "path": "/b", "value": 1},
+		{"op": "add", "path": "/a/0", "value": 0},
+		{"op": "move", "from": "/b", "path": "/c"}
+	]`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"diff", doc, other}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("bad exit code %d, stderr: %s", code, stderr.String())
+	}
+
+	expected := `[
+  {
+    "op": "remove",
+    "path": "/a/1"
+  },
+  {
+    "op": "replace",
+    "path": "/b",
+    "value": 2
+  },
+  {
+    "op": "add",
+    "path": "/c",
+    "value": null
+  }
+]
+`
+	if stdout.String() != expected {
+		t.Fatalf("bad diff: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"patch", "-w", patch, doc}, nil, &stdout, &stderr); code != exitOK {
+		t.Fatalf("bad exit code %d, stderr: %s", code, stderr.String())
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("bad stdout: %s", stdout.String())
+	}
+
+	actual, err := ioutil.ReadFile(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(actual) != "{\n  \"a\": [0,1, 2],\"c\":1\n}\n" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	// A failed patch leaves the file unmodified
+	if code := run([]string{"patch", "-w", patch, doc}, nil, &stdout, &stderr); code != exitNotFound {
+		t.Fatalf("bad exit code %d", code)
+	}
+
+	unmodified, err := ioutil.ReadFile(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(actual, unmodified) {
+		t.Fatalf("modified: %s", unmodified)
+	}
+
+	// Writing in place requires a file
+	if code := run([]string{"delete", "-w", "/a"}, strings.NewReader(`{"a":1}`), &stdout, &stderr); code != exitError {
+		t.Fatalf("bad exit code %d", code)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/mitchellh/pointerstructure"
+)
+
+// pointerString returns the pointer string for the parts.
+func pointerString(parts []string) string {
+	return (&pointerstructure.Pointer{Parts: parts}).String()
+}
+
+// child returns parts with part appended, without sharing parts.
+func child(parts []string, part string) []string {
+	result := make([]string, len(parts), len(parts)+1)
+	copy(result, parts)
+	return append(result, part)
+}
+
+// diff returns the patch that turns the decoded JSON value a at parts into
+// b. Objects and arrays are compared recursively.
+func diff(parts []string, a, b interface{}) pointerstructure.Patch {
+	var patch pointerstructure.Patch
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for _, k := range sortedKeys(a) {
+			if bv, ok := b[k]; ok {
+				patch = append(patch, diff(child(parts, k), a[k], bv)...)
+			} else {
+				patch = append(patch, pointerstructure.Operation{
+					Op:   "remove",
+					Path: pointerString(child(parts, k)),
+				})
+			}
+		}
+
+		for _, k := range sortedKeys(b) {
+			if _, ok := a[k]; !ok {
+				patch = append(patch, pointerstructure.Operation{
+					Op:    "add",
+					Path:  pointerString(child(parts, k)),
+					Value: b[k],
+				})
+			}
+		}
+
+		return patch
+
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(a) && i < len(b); i++ {
+			patch = append(patch, diff(child(parts, fmt.Sprint(i)), a[i], b[i])...)
+		}
+
+		for i := len(a); i < len(b); i++ {
+			patch = append(patch, pointerstructure.Operation{
+				Op:    "add",
+				Path:  pointerString(child(parts, fmt.Sprint(i))),
+				Value: b[i],
+			})
+		}
+
+		// Remove from the end so that the indexes stay valid
+		for i := len(a) - 1; i >= len(b); i-- {
+			patch = append(patch, pointerstructure.Operation{
+				Op:   "remove",
+				Path: pointerString(child(parts, fmt.Sprint(i))),
+			})
+		}
+
+		return patch
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+
+	return pointerstructure.Patch{{
+		Op:    "replace",
+		Path:  pointerString(parts),
+		Value: b,
+	}}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// flatten writes the pointer and value of each leaf of the JSON value read
+// from dec, in document order. Empty objects and arrays are leaves.
+func flatten(dec *json.Decoder, parts []string, w io.Writer) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		value, err := encodeJSON(tok)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "%s\t%s\n", pointerString(parts), value)
+		return err
+	}
+
+	empty := "{}"
+	if delim == '[' {
+		empty = "[]"
+	}
+
+	if !dec.More() {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+
+		_, err := fmt.Fprintf(w, "%s\t%s\n", pointerString(parts), empty)
+		return err
+	}
+
+	for i := 0; dec.More(); i++ {
+		part := fmt.Sprint(i)
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			part = key.(string)
+		}
+
+		if err := flatten(dec, child(parts, part), w); err != nil {
+			return err
+		}
+	}
+
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// list writes the pointers of the children of the JSON object or array
+// value at parts, in document order.
+func list(value json.RawMessage, parts []string, w io.Writer) error {
+	dec := json.NewDecoder(bytes.NewReader(value))
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return fmt.Errorf("%s: %w: not an object or array",
+			pointerString(parts), pointerstructure.ErrInvalidKind)
+	}
+
+	for i := 0; dec.More(); i++ {
+		part := fmt.Sprint(i)
+		if delim == '{' {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			part = key.(string)
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w, pointerString(child(parts, part))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
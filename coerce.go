@@ -0,0 +1,219 @@
+package pointerstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// Coercer converts values to the types required by the structure being
+// accessed. The Coercer is set with Config.Coercer and defaults to
+// WeakCoercer.
+type Coercer interface {
+	// CoerceKey converts a pointer part to the key type of a map, or to
+	// an int for the index of a slice or array.
+	CoerceKey(part string, to reflect.Type) (reflect.Value, error)
+
+	// CoerceValue converts a value given to Set to the type of the map
+	// element, slice element, or struct field it is written to.
+	CoerceValue(value reflect.Value, to reflect.Type) (reflect.Value, error)
+}
+
+// StrictCoercer is a Coercer that only accepts values that are assignable
+// to the target type. Keys are parsed from pointer parts only if they are
+// the exact textual representation of a number or bool.
+type StrictCoercer struct{}
+
+func (StrictCoercer) CoerceKey(part string, to reflect.Type) (reflect.Value, error) {
+	return parseKey(part, to)
+}
+
+func (StrictCoercer) CoerceValue(value reflect.Value, to reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		return zeroNilable(to)
+	}
+
+	if value.Type().AssignableTo(to) {
+		return value, nil
+	}
+
+	return reflect.Value{}, convertError(value.Interface(), to)
+}
+
+// ConvertCoercer is a Coercer that accepts values that are assignable or
+// can be converted to the target type with a Go conversion, such as int to
+// float64 or a string to a named string type. Signed and unsigned integers
+// are never converted to strings since that conversion results in a rune rather than the
+// number. Keys are parsed like StrictCoercer.
+type ConvertCoercer struct{}
+
+func (ConvertCoercer) CoerceKey(part string, to reflect.Type) (reflect.Value, error) {
+	return parseKey(part, to)
+}
+
+func (ConvertCoercer) CoerceValue(value reflect.Value, to reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		return zeroNilable(to)
+	}
+
+	if value.Type().AssignableTo(to) {
+		return value, nil
+	}
+
+	k := value.Kind()
+	if value.Type().ConvertibleTo(to) && !((isInt(k) || isUint(k)) && to.Kind() == reflect.String) {
+		return value.Convert(to), nil
+	}
+
+	return reflect.Value{}, convertError(value.Interface(), to)
+}
+
+// WeakCoercer is a Coercer that falls back to mapstructure's weakly typed
+// decoding if a value isn't assignable or convertible to the target type.
+// For example, "true" can be written to a bool and "42" to an int. This
+// is the default Coercer.
+type WeakCoercer struct {
+	// DecodeHook, if set, is used as the mapstructure decode hook when
+	// decoding the value.
+	DecodeHook mapstructure.DecodeHookFunc
+}
+
+func (c WeakCoercer) CoerceKey(part string, to reflect.Type) (reflect.Value, error) {
+	return c.CoerceValue(reflect.ValueOf(part), to)
+}
+
+func (c WeakCoercer) CoerceValue(value reflect.Value, to reflect.Type) (reflect.Value, error) {
+	if !value.IsValid() {
+		return reflect.Zero(to), nil
+	}
+
+	// If the value is already assignable to the type, then let it go
+	if value.Type().AssignableTo(to) {
+		return value, nil
+	}
+
+	// If a direct conversion is possible, do that
+	if value.Type().ConvertibleTo(to) {
+		return value.Convert(to), nil
+	}
+
+	// Create a new value to hold our result
+	result := reflect.New(to)
+
+	// Decode
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       c.DecodeHook,
+		WeaklyTypedInput: true,
+		Result:           result.Interface(),
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := decoder.Decode(value.Interface()); err != nil {
+		return result, convertError(value.Interface(), to)
+	}
+
+	// We need to indirect the value since reflect.New always creates a pointer
+	return reflect.Indirect(result), nil
+}
+
+func (c *Config) coercer() Coercer {
+	if c.Coercer == nil {
+		return WeakCoercer{}
+	}
+
+	return c.Coercer
+}
+
+// coerceKey converts a pointer part to the type to using the configured
+// Coercer.
+func (c *Config) coerceKey(part string, to reflect.Type) (reflect.Value, error) {
+	return c.coercer().CoerceKey(part, to)
+}
+
+// coerceValue converts a value to the type to using the configured Coercer.
+func (c *Config) coerceValue(value reflect.Value, to reflect.Type) (reflect.Value, error) {
+	return c.coercer().CoerceValue(value, to)
+}
+
+// parseKey parses a pointer part into the type to. Only the exact textual
+// representation of a value is accepted.
+func parseKey(part string, to reflect.Type) (reflect.Value, error) {
+	result := reflect.New(to).Elem()
+	switch {
+	case to.Kind() == reflect.String:
+		result.SetString(part)
+
+	case isInt(to.Kind()):
+		i, err := strconv.ParseInt(part, 10, to.Bits())
+		if err != nil {
+			return result, convertError(part, to)
+		}
+		result.SetInt(i)
+
+	case isUint(to.Kind()):
+		u, err := strconv.ParseUint(part, 10, to.Bits())
+		if err != nil {
+			return result, convertError(part, to)
+		}
+		result.SetUint(u)
+
+	case to.Kind() == reflect.Float32 || to.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(part, to.Bits())
+		if err != nil {
+			return result, convertError(part, to)
+		}
+		result.SetFloat(f)
+
+	case to.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(part)
+		if err != nil {
+			return result, convertError(part, to)
+		}
+		result.SetBool(b)
+
+	case reflect.TypeOf(part).AssignableTo(to):
+		// For example, interface{} keys
+		result.Set(reflect.ValueOf(part))
+
+	default:
+		return result, convertError(part, to)
+	}
+
+	return result, nil
+}
+
+// zeroNilable returns the zero value for a nil value assigned to type to,
+// which is only possible if to can be nil.
+func zeroNilable(to reflect.Type) (reflect.Value, error) {
+	switch to.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return reflect.Zero(to), nil
+	}
+
+	return reflect.Value{}, convertError(nil, to)
+}
+
+func convertError(value interface{}, to reflect.Type) error {
+	return fmt.Errorf("%w %#v to type %s", ErrConvert, value, to.String())
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+func TestCoercer_interface(t *testing.T) {
+	var _ Coercer = StrictCoercer{}
+	var _ Coercer = ConvertCoercer{}
+	var _ Coercer = WeakCoercer{}
+}
+
+func TestPointerSet_coercer(t *testing.T) {
+	type testStringType string
+
+	cases := []struct {
+		Name    string
+		Coercer Coercer
+		Pointer string
+		Doc     interface{}
+		Value   interface{}
+		Output  interface{}
+		Err     bool
+	}{
+		{
+			"weak default string to int",
+			nil,
+			"/foo",
+			map[string]int{},
+			"42",
+			map[string]int{"foo": 42},
+			false,
+		},
+
+		{
+			"weak default empty string to int",
+			nil,
+			"/foo",
+			map[string]int{},
+			"",
+			map[string]int{"foo": 0},
+			false,
+		},
+
+		{
+			"weak nil",
+			nil,
+			"/foo",
+			map[string]int{},
+			nil,
+			map[string]int{"foo": 0},
+			false,
+		},
+
+		{
+			"weak decode hook",
+			WeakCoercer{DecodeHook: mapstructure.StringToTimeDurationHookFunc()},
+			"/foo",
+			map[string]time.Duration{},
+			"5s",
+			map[string]time.Duration{"foo": 5 * time.Second},
+			false,
+		},
+
+		{
+			"strict assignable",
+			StrictCoercer{},
+			"/0",
+			[]interface{}{1},
+			"foo",
+			[]interface{}{"foo"},
+			false,
+		},
+
+		{
+			"strict string to int",
+			StrictCoercer{},
+			"/foo",
+			map[string]int{},
+			"42",
+			nil,
+			true,
+		},
+
+		{
+			"strict convertible",
+			StrictCoercer{},
+			"/foo",
+			map[string]int64{},
+			42,
+			nil,
+			true,
+		},
+
+		{
+			"strict nil to interface",
+			StrictCoercer{},
+			"/foo",
+			map[string]interface{}{},
+			nil,
+			map[string]interface{}{"foo": nil},
+			false,
+		},
+
+		{
+			"strict nil to int",
+			StrictCoercer{},
+			"/foo",
+			map[string]int{},
+			nil,
+			nil,
+			true,
+		},
+
+		{
+			"strict int key",
+			StrictCoercer{},
+			"/42",
+			map[int]string{},
+			"foo",
+			map[int]string{42: "foo"},
+			false,
+		},
+
+		{
+			"strict empty key",
+			StrictCoercer{},
+			"/",
+			map[int]string{},
+			"foo",
+			nil,
+			true,
+		},
+
+		{
+			"convert int to int64",
+			ConvertCoercer{},
+			"/foo",
+			map[string]int64{},
+			42,
+			map[string]int64{"foo": 42},
+			false,
+		},
+
+		{
+			"convert named string",
+			ConvertCoercer{},
+			"/foo",
+			map[string]testStringType{},
+			"bar",
+			map[string]testStringType{"foo": "bar"},
+			false,
+		},
+
+		{
+			"convert int to string",
+			ConvertCoercer{},
+			"/foo",
+			map[string]string{},
+			65,
+			nil,
+			true,
+		},
+
+		{
+			"convert uint to string",
+			ConvertCoercer{},
+			"/foo",
+			map[string]string{},
+			uint(65),
+			nil,
+			true,
+		},
+
+		{
+			"convert string to int",
+			ConvertCoercer{},
+			"/foo",
+			map[string]int{},
+			"42",
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.Coercer = tc.Coercer
+			actual, err := p.Set(tc.Doc, tc.Value)
+			if (err != nil) != tc.Err {
+				t.Fatalf("err: %s", err)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrConvert) {
+					t.Fatalf("expected ErrConvert, got: %s", err)
+				}
+				return
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v != %#v", actual, tc.Output)
+			}
+		})
+	}
+}
+
+func TestPointerGet_coercer(t *testing.T) {
+	doc := []interface{}{"a", "b"}
+
+	// Weak decoding turns an empty part into index 0
+	if _, err := Get(doc, "/"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := MustParse("/")
+	p.Config.Coercer = StrictCoercer{}
+	if _, err := p.Get(doc); !errors.Is(err, ErrConvert) {
+		t.Fatalf("expected ErrConvert, got: %v", err)
+	}
+
+	p = MustParse("/1")
+	p.Config.Coercer = StrictCoercer{}
+	actual, err := p.Get(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != "b" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
@@ -11,24 +11,41 @@ import (
 // the left. This is specified in RFC6902 (JSON Patch) and not RFC6901 since
 // RFC6901 doesn't specify operations on pointers. If you don't want to
 // shift elements, you should use Set to set the slice index to the zero value.
+// Array elements can only be deleted with Config.ArrayDeleteShift.
+//
+// Errors from deleting the value wrap the underlying error, so errors.Is
+// can be used to check for ErrOutOfRange or ErrInvalidKind.
 //
 // The structures s must have non-zero values set up to this pointer.
 // For example, if deleting "/bob/0/name", then "/bob/0" must be set already.
 //
+// Structs and arrays stored in maps are copied, modified and stored back
+// into the map.
+//
+// If the parent of the pointer implements PointerDeleter, it is used to
+// delete the value rather than reflection.
+//
 // The returned value is potentially a new value if this pointer represents
-// the root document. Otherwise, the returned value will always be s.
+// the root document. Otherwise, the returned value will always be s, unless
+// Config.Immutable is set in which case it is a modified copy of s.
 func (p *Pointer) Delete(s interface{}) (interface{}, error) {
 	// if we represent the root doc, we've deleted everything
 	if len(p.Parts) == 0 {
 		return nil, nil
 	}
 
-	// Save the original since this is going to be our return value
-	originalS := s
+	// Values within a json.RawMessage are modified in a decoded copy
+	if p.Config.DecodeRawJSON {
+		result, ok, err := p.modifyRawJSON(s, func(sub *Pointer, doc interface{}) (interface{}, error) {
+			return sub.Delete(doc)
+		})
+		if ok {
+			return result, err
+		}
+	}
 
 	// Get the parent value
-	var err error
-	s, err = p.Parent().Get(s)
+	parent, err := p.parent(s)
 	if err != nil {
 		return nil, err
 	}
@@ -40,13 +57,13 @@ func (p *Pointer) Delete(s interface{}) (interface{}, error) {
 		reflect.Slice: p.deleteSlice,
 	}
 
-	val := reflect.ValueOf(s)
-	for val.Kind() == reflect.Interface {
-		val = val.Elem()
-	}
+	val, r := indirectResolver(parent.value, pointerDeleterType)
+	if deleter, ok := r.(PointerDeleter); ok {
+		if err := deleter.PointerDelete(p.Parts[len(p.Parts)-1]); err != nil {
+			return nil, fmt.Errorf("delete %s: %w", p, err)
+		}
 
-	for val.Kind() == reflect.Ptr {
-		val = reflect.Indirect(val)
+		return p.writeBack(s, parent, val, reflect.Value{})
 	}
 
 	f, ok := funcMap[val.Kind()]
@@ -54,42 +71,45 @@ func (p *Pointer) Delete(s interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("delete %s: %w: %s", p, ErrInvalidKind, val.Kind())
 	}
 
-	result, err := f(originalS, val)
+	replaced, err := f(val)
 	if err != nil {
-		return nil, fmt.Errorf("delete %s: %s", p, err)
+		return nil, fmt.Errorf("delete %s: %w", p, err)
 	}
 
-	return result, nil
+	return p.writeBack(s, parent, val, replaced)
 }
 
-type deleteFunc func(interface{}, reflect.Value) (interface{}, error)
+// deleteFunc deletes the value for the last part of the pointer from the
+// container s. If the container itself had to be replaced, such as when
+// removing an element from a slice, the new container is returned.
+type deleteFunc func(s reflect.Value) (reflect.Value, error)
+
+func (p *Pointer) deleteMap(m reflect.Value) (reflect.Value, error) {
+	var zeroValue reflect.Value
 
-func (p *Pointer) deleteMap(root interface{}, m reflect.Value) (interface{}, error) {
 	part := p.Parts[len(p.Parts)-1]
-	key, err := coerce(reflect.ValueOf(part), m.Type().Key())
+	key, _, err := p.mapKey(part, m)
 	if err != nil {
-		return root, err
+		return zeroValue, err
 	}
 
 	// Delete the key
 	var elem reflect.Value
 	m.SetMapIndex(key, elem)
-	return root, nil
+	return zeroValue, nil
 }
 
-func (p *Pointer) deleteSlice(root interface{}, s reflect.Value) (interface{}, error) {
-	// Coerce the key to an int
+func (p *Pointer) deleteSlice(s reflect.Value) (reflect.Value, error) {
+	var zeroValue reflect.Value
+
 	part := p.Parts[len(p.Parts)-1]
-	idxVal, err := coerce(reflect.ValueOf(part), reflect.TypeOf(42))
+	idx, err := p.sliceIndex(part, s.Len())
 	if err != nil {
-		return root, err
+		return zeroValue, err
 	}
-	idx := int(idxVal.Int())
 
-	// Verify we're within bounds
-	if idx < 0 || idx >= s.Len() {
-		return root, fmt.Errorf(
-			"index %d is %w (length = %d)", idx, ErrOutOfRange, s.Len())
+	if s.Kind() == reflect.Array {
+		return p.deleteArray(s, idx)
 	}
 
 	// Mimicing the following with reflection to do this:
@@ -107,6 +127,30 @@ func (p *Pointer) deleteSlice(root interface{}, s reflect.Value) (interface{}, e
 	// a = a[:len(a)-1]
 	s = s.Slice(0, s.Len()-1)
 
-	// set the slice back on the parent
-	return p.Parent().Set(root, s.Interface())
+	// return the slice so it is set back on the parent
+	return s, nil
+}
+
+// deleteArray deletes the element at idx from the array s. Since the length
+// of an array is fixed, this is only allowed with Config.ArrayDeleteShift,
+// in which case the following elements are shifted left and the last
+// element is set to the zero value.
+func (p *Pointer) deleteArray(s reflect.Value, idx int) (reflect.Value, error) {
+	var zeroValue reflect.Value
+
+	if !p.Config.ArrayDeleteShift {
+		return zeroValue, fmt.Errorf(
+			"%w: cannot delete from array %s", ErrInvalidKind, s.Type())
+	}
+
+	if !s.CanAddr() {
+		return zeroValue, fmt.Errorf("array index %d cannot be deleted", idx)
+	}
+
+	for i := idx; i < s.Len()-1; i++ {
+		s.Index(i).Set(s.Index(i + 1))
+	}
+	s.Index(s.Len() - 1).Set(reflect.Zero(s.Type().Elem()))
+
+	return zeroValue, nil
 }
@@ -1,6 +1,7 @@
 package pointerstructure
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -89,3 +90,138 @@ func TestPointerDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerDelete_immutable(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": []interface{}{1, 2, 3},
+		"b": map[string]interface{}{"c": 1},
+	}
+
+	cases := []struct {
+		Pointer string
+		Output  interface{}
+	}{
+		{
+			"/a/0",
+			map[string]interface{}{
+				"a": []interface{}{2, 3},
+				"b": map[string]interface{}{"c": 1},
+			},
+		},
+		{
+			"/b/c",
+			map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+				"b": map[string]interface{}{},
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.Immutable = true
+			actual, err := p.Delete(doc)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v != %#v", actual, tc.Output)
+			}
+
+			expected := map[string]interface{}{
+				"a": []interface{}{1, 2, 3},
+				"b": map[string]interface{}{"c": 1},
+			}
+			if !reflect.DeepEqual(doc, expected) {
+				t.Fatalf("input modified: %#v", doc)
+			}
+		})
+	}
+}
+
+func TestPointerDelete_mapValues(t *testing.T) {
+	type server struct {
+		Tags  []string
+		Attrs map[string]string
+	}
+
+	doc := map[string]server{
+		"a": {
+			Tags:  []string{"x", "y"},
+			Attrs: map[string]string{"k": "v"},
+		},
+	}
+
+	for _, ptr := range []string{"/a/Tags/0", "/a/Attrs/k"} {
+		if _, err := MustParse(ptr).Delete(doc); err != nil {
+			t.Fatalf("%s: %s", ptr, err)
+		}
+	}
+
+	expected := map[string]server{
+		"a": {
+			Tags:  []string{"y"},
+			Attrs: map[string]string{},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("bad: %#v", doc)
+	}
+}
+
+func TestPointerDelete_array(t *testing.T) {
+	doc := map[string][3]int{"a": {1, 2, 3}}
+
+	// Deleting from an array is rejected by default
+	_, err := MustParse("/a/0").Delete(doc)
+	if !errors.Is(err, ErrInvalidKind) {
+		t.Fatalf("expected ErrInvalidKind, got: %v", err)
+	}
+
+	p := MustParse("/a/0")
+	p.Config.ArrayDeleteShift = true
+	if _, err := p.Delete(doc); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if doc["a"] != [3]int{2, 3, 0} {
+		t.Fatalf("bad: %#v", doc)
+	}
+
+	p = MustParse("/a/1")
+	p.Config.ArrayDeleteShift = true
+	p.Config.Immutable = true
+	actual, err := p.Delete(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if doc["a"] != [3]int{2, 3, 0} {
+		t.Fatalf("input modified: %#v", doc)
+	}
+	if actual.(map[string][3]int)["a"] != [3]int{2, 0, 0} {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestPointerDelete_negativeIndex(t *testing.T) {
+	p := MustParse("/-2")
+	p.Config.NegativeIndex = true
+	actual, err := p.Delete([]interface{}{1, 2, 3})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(actual, []interface{}{1, 3}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestPointerDelete_errorWrapping(t *testing.T) {
+	_, err := MustParse("/a/5").Delete(map[string]interface{}{
+		"a": []interface{}{1},
+	})
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("expected ErrOutOfRange, got: %v", err)
+	}
+}
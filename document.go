@@ -0,0 +1,205 @@
+package pointerstructure
+
+import (
+	"errors"
+	"sync"
+)
+
+// Document is a value that can be read and modified by pointer from
+// multiple goroutines concurrently.
+//
+// Modifications are copy-on-write using Config.Immutable, so values
+// returned by Get and Snapshot are never modified by later changes to the
+// document. Values returned by Get share memory with the document and
+// must not be modified themselves, use Snapshot for a copy that can be.
+// Values implementing PointerSetter or PointerDeleter are modified in
+// place and so must be safe for concurrent use themselves.
+type Document struct {
+	// mu guards root, which is only replaced while holding updateMu too
+	// so that updates are serialized without blocking reads.
+	mu       sync.RWMutex
+	updateMu sync.Mutex
+	root     interface{}
+	config   Config
+}
+
+// NewDocument returns a document with the given root value, using the
+// Config for its pointers. The document takes ownership of root, so it
+// must not be modified other than through the document afterwards.
+func NewDocument(root interface{}, c Config) *Document {
+	c.Immutable = true
+	return &Document{root: root, config: c}
+}
+
+// Get reads the value at the pointer, like Get.
+func (d *Document) Get(pointer string) (interface{}, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	tx := &Tx{root: d.root, config: d.config}
+	return tx.Get(pointer)
+}
+
+// Snapshot returns a deep copy of the current root value of the document.
+func (d *Document) Snapshot() (interface{}, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return CloneWithConfig(d.root, d.config)
+}
+
+// Set sets the value at the pointer, like Set.
+func (d *Document) Set(pointer string, value interface{}) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.Set(pointer, value)
+	})
+}
+
+// Delete deletes the value at the pointer, like Delete.
+func (d *Document) Delete(pointer string) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.Delete(pointer)
+	})
+}
+
+// Apply applies the patch to the document. Either the whole patch is
+// applied, or if an operation fails the document is unchanged.
+func (d *Document) Apply(patch Patch) error {
+	return d.Update(func(tx *Tx) error {
+		return tx.Apply(patch)
+	})
+}
+
+// Update calls fn with a transaction for modifying the document. If fn
+// returns nil, the changes made in the transaction are committed at once.
+// If fn returns an error or panics, the document is unchanged.
+//
+// Other updates wait for the transaction to finish, while reads continue
+// to see the document as it was before the transaction. The transaction
+// must not be used after fn returns.
+func (d *Document) Update(fn func(tx *Tx) error) error {
+	d.updateMu.Lock()
+	defer d.updateMu.Unlock()
+
+	// Only updates replace the root, so it can be read without mu here
+	tx := &Tx{root: d.root, config: d.config}
+	defer tx.finish()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	root := tx.finish()
+	d.mu.Lock()
+	d.root = root
+	d.mu.Unlock()
+	return nil
+}
+
+// Tx is a transaction for modifying a Document, see Document.Update. It is
+// safe for concurrent use, but using it after Update returns is an error.
+type Tx struct {
+	mu     sync.Mutex
+	root   interface{}
+	config Config
+	done   bool
+}
+
+// errTxDone is returned when a transaction is used after Update returned.
+var errTxDone = errors.New("transaction has already finished")
+
+// Get reads the value at the pointer, including the changes made in the
+// transaction so far.
+func (tx *Tx) Get(pointer string) (interface{}, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p, err := tx.pointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Get(tx.root)
+}
+
+// Set sets the value at the pointer.
+func (tx *Tx) Set(pointer string, value interface{}) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p, err := tx.pointer(pointer)
+	if err != nil {
+		return err
+	}
+
+	root, err := p.Set(tx.root, value)
+	if err != nil {
+		return err
+	}
+
+	tx.root = root
+	return nil
+}
+
+// Delete deletes the value at the pointer.
+func (tx *Tx) Delete(pointer string) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	p, err := tx.pointer(pointer)
+	if err != nil {
+		return err
+	}
+
+	root, err := p.Delete(tx.root)
+	if err != nil {
+		return err
+	}
+
+	tx.root = root
+	return nil
+}
+
+// Apply applies the patch. If an operation fails, none of the patch is
+// applied.
+func (tx *Tx) Apply(patch Patch) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return errTxDone
+	}
+
+	root, err := patch.ApplyWithConfig(tx.root, tx.config)
+	if err != nil {
+		return err
+	}
+
+	tx.root = root
+	return nil
+}
+
+// finish marks the transaction as finished and returns its root.
+func (tx *Tx) finish() interface{} {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.done = true
+	return tx.root
+}
+
+// pointer parses the pointer with the Config of the document. tx.mu must
+// be held.
+func (tx *Tx) pointer(pointer string) (*Pointer, error) {
+	if tx.done {
+		return nil, errTxDone
+	}
+
+	p, err := Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Config = tx.config
+	return p, nil
+}
@@ -0,0 +1,197 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	d := NewDocument(map[string]interface{}{
+		"server": map[string]interface{}{"port": 80},
+		"hosts":  []interface{}{"a"},
+	}, Config{})
+
+	before, err := d.Get("/server")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := d.Set("/server/port", 443); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := d.Delete("/hosts/0"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := d.Apply(Patch{{Op: "add", Path: "/hosts/-", Value: "b"}}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Values already read are unaffected by later changes
+	if !reflect.DeepEqual(before, map[string]interface{}{"port": 80}) {
+		t.Fatalf("bad: %#v", before)
+	}
+
+	snapshot, err := d.Snapshot()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"server": map[string]interface{}{"port": 443},
+		"hosts":  []interface{}{"b"},
+	}
+	if !reflect.DeepEqual(snapshot, expected) {
+		t.Fatalf("bad: %#v", snapshot)
+	}
+
+	// The snapshot is a copy
+	snapshot.(map[string]interface{})["server"].(map[string]interface{})["port"] = 1
+	if port, _ := d.Get("/server/port"); port != 443 {
+		t.Fatalf("bad: %#v", port)
+	}
+
+	if _, err := d.Get("/missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestDocument_Update(t *testing.T) {
+	d := NewDocument(map[string]interface{}{"a": 1, "b": 2}, Config{})
+
+	var leaked *Tx
+	err := d.Update(func(tx *Tx) error {
+		leaked = tx
+		if err := tx.Set("/a", 10); err != nil {
+			return err
+		}
+
+		// Changes are visible within the transaction only
+		if v, _ := tx.Get("/a"); v != 10 {
+			t.Fatalf("bad: %#v", v)
+		}
+		if v, _ := d.Get("/a"); v != 1 {
+			t.Fatalf("bad: %#v", v)
+		}
+
+		return tx.Delete("/b")
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if err := leaked.Set("/a", 0); err == nil {
+		t.Fatal("expected error using finished transaction")
+	}
+
+	// A transaction used from another goroutine while Update returns
+	// doesn't race, and its changes are either committed or rejected
+	started := make(chan struct{})
+	finished := make(chan error)
+	err = d.Update(func(tx *Tx) error {
+		go func() {
+			close(started)
+			finished <- tx.Set("/c", 3)
+		}()
+		<-started
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := <-finished; err != nil && err != errTxDone {
+		t.Fatalf("err: %s", err)
+	}
+	if err := d.Delete("/c"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// A failed transaction is rolled back
+	err = d.Update(func(tx *Tx) error {
+		if err := tx.Set("/a", 20); err != nil {
+			return err
+		}
+
+		return tx.Apply(Patch{{Op: "remove", Path: "/missing"}})
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+
+	// A panicking transaction is rolled back
+	func() {
+		defer func() { recover() }()
+		d.Update(func(tx *Tx) error {
+			tx.Set("/a", 30)
+			panic("boom")
+		})
+	}()
+
+	snapshot, err := d.Snapshot()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(snapshot, map[string]interface{}{"a": 10}) {
+		t.Fatalf("bad: %#v", snapshot)
+	}
+}
+
+func TestDocument_concurrent(t *testing.T) {
+	type config struct {
+		Counter int
+		Items   map[string]int
+	}
+
+	d := NewDocument(&config{Items: map[string]int{}}, Config{})
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(3)
+
+		go func(i int) {
+			defer wg.Done()
+			err := d.Update(func(tx *Tx) error {
+				v, err := tx.Get("/Counter")
+				if err != nil {
+					return err
+				}
+
+				return tx.Set("/Counter", v.(int)+1)
+			})
+			if err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}(i)
+
+		go func(i int) {
+			defer wg.Done()
+			if err := d.Set(fmt.Sprintf("/Items/%d", i), i); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			if _, err := d.Get("/Items"); err != nil {
+				t.Errorf("err: %s", err)
+			}
+			if _, err := d.Snapshot(); err != nil {
+				t.Errorf("err: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot, err := d.Snapshot()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := snapshot.(*config)
+	if result.Counter != n || len(result.Items) != n {
+		t.Fatalf("bad: %d, %d", result.Counter, len(result.Items))
+	}
+}
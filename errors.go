@@ -19,4 +19,16 @@ var (
 
 	// ErrConvert is returned if an item is not of a requested type
 	ErrConvert = errors.New("couldn't convert value")
+
+	// ErrAmbiguous is returned if a part matches more than one key or
+	// field when matching isn't exact, see Config.CaseInsensitive
+	ErrAmbiguous = errors.New("ambiguous key")
+
+	// ErrRefCycle is returned if resolving a $ref leads back to the same
+	// $ref
+	ErrRefCycle = errors.New("reference cycle")
+
+	// ErrTestFailed is returned if the value of a "test" operation of a
+	// Patch doesn't match
+	ErrTestFailed = errors.New("test failed")
 )
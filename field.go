@@ -0,0 +1,319 @@
+package pointerstructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// field is a single addressable field of a struct type, including fields
+// promoted from embedded structs.
+type field struct {
+	// name is the name used to address the field in a pointer.
+	name string
+
+	// index is the index sequence for reaching the field, as used by
+	// reflect.Value.FieldByIndex.
+	index []int
+
+	// typ is the type of the field.
+	typ reflect.Type
+
+	// tagged is true if the name came from a struct tag.
+	tagged bool
+
+	// secret is true if the field has the "secret" tag option, see Redact.
+	secret bool
+}
+
+// structFields is the set of fields that can be addressed on a struct type.
+type structFields struct {
+	list    []field
+	byName  map[string]int
+	ignored map[string]struct{}
+}
+
+type structFieldsKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+type structFieldsResult struct {
+	fields *structFields
+	err    error
+}
+
+var structFieldsCache sync.Map // map[structFieldsKey]structFieldsResult
+
+// cachedStructFields is like typeStructFields but caches the result since
+// computing it for deeply embedded types is not cheap.
+func cachedStructFields(t reflect.Type, tagName string) (*structFields, error) {
+	key := structFieldsKey{typ: t, tagName: tagName}
+	if r, ok := structFieldsCache.Load(key); ok {
+		result := r.(structFieldsResult)
+		return result.fields, result.err
+	}
+
+	fields, err := typeStructFields(t, tagName)
+	r, _ := structFieldsCache.LoadOrStore(key, structFieldsResult{fields, err})
+	result := r.(structFieldsResult)
+	return result.fields, result.err
+}
+
+// typeStructFields returns the fields that can be addressed for the given
+// struct type. The rules mirror encoding/json: fields of embedded structs
+// are promoted to the parent, shallower fields hide deeper ones, and a
+// tagged field wins over untagged fields at the same depth. If multiple
+// fields still conflict, none of them is addressable.
+func typeStructFields(t reflect.Type, tagName string) (*structFields, error) {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	current := []queued{}
+	next := []queued{{typ: t}}
+
+	// Count of queued names for the current and next level, to detect
+	// whether an embedded type is reachable multiple times at one depth.
+	var count, nextCount map[reflect.Type]int
+
+	// Types already visited at an earlier level.
+	visited := map[reflect.Type]bool{}
+
+	var fields []field
+	ignored := map[string]struct{}{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[reflect.Type]int{}
+
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				unexported := sf.PkgPath != ""
+				if sf.Anonymous {
+					ft := sf.Type
+					if ft.Kind() == reflect.Ptr {
+						ft = ft.Elem()
+					}
+					if unexported && ft.Kind() != reflect.Struct {
+						// this is an unexported non-struct embed so ignore it
+						continue
+					}
+				} else if unexported {
+					// this is an unexported field so ignore it
+					continue
+				}
+
+				tag := sf.Tag.Get(tagName)
+				var opts []string
+				if idx := strings.Index(tag, ","); idx != -1 {
+					tag, opts = tag[0:idx], strings.Split(tag[idx+1:], ",")
+				}
+
+				if strings.Contains(tag, "|") {
+					// should this panic instead?
+					return nil, fmt.Errorf("pointer struct tag cannot contain the '|' character")
+				}
+
+				if tag == "-" {
+					// we should ignore this field but remember the name since
+					// it is possible another field assumes the name.
+					if len(q.index) == 0 {
+						ignored[sf.Name] = struct{}{}
+					}
+					continue
+				}
+
+				if unexported {
+					// an unexported embedded struct can't be addressed
+					// itself, so a name in its tag is ignored and its
+					// fields are promoted
+					tag = ""
+				}
+
+				index := make([]int, len(q.index)+1)
+				copy(index, q.index)
+				index[len(q.index)] = i
+
+				ft := sf.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				// Record the field unless it is an untagged embedded struct,
+				// in which case its fields are promoted at the next level.
+				if tag != "" || !sf.Anonymous || ft.Kind() != reflect.Struct {
+					name := tag
+					if name == "" {
+						name = sf.Name
+					}
+
+					fields = append(fields, field{
+						name:   name,
+						index:  index,
+						typ:    sf.Type,
+						tagged: tag != "",
+						secret: hasTagOption(opts, "secret"),
+					})
+
+					if count[q.typ] > 1 {
+						// If there were multiple instances of the embedding
+						// type, add the field twice so that the conflict
+						// below annihilates it.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, queued{typ: ft, index: index})
+				}
+			}
+		}
+	}
+
+	// Sort by name, then depth, then tagged so that the dominant field for
+	// each name comes first within its group.
+	sort.SliceStable(fields, func(i, j int) bool {
+		x := fields
+		if x[i].name != x[j].name {
+			return x[i].name < x[j].name
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tagged != x[j].tagged {
+			return x[i].tagged
+		}
+		return false
+	})
+
+	result := &structFields{
+		byName:  map[string]int{},
+		ignored: ignored,
+	}
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		fi := fields[i]
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].name != fi.name {
+				break
+			}
+		}
+
+		if advance == 1 {
+			result.list = append(result.list, fi)
+			continue
+		}
+
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			result.list = append(result.list, dominant)
+		}
+	}
+
+	// Restore the declaration order so callers can list fields predictably.
+	sort.Slice(result.list, func(i, j int) bool {
+		return indexLess(result.list[i].index, result.list[j].index)
+	})
+
+	for i, f := range result.list {
+		result.byName[f.name] = i
+	}
+
+	return result, nil
+}
+
+func hasTagOption(opts []string, name string) bool {
+	for _, opt := range opts {
+		if opt == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dominantField looks through the fields, all of which are known to have
+// the same name, to find the single field that dominates the others.
+// The fields are sorted by depth, then by whether they are tagged.
+func dominantField(fields []field) (field, bool) {
+	// If there are multiple top-level fields and the first isn't the only
+	// tagged one, the fields conflict and none of them is addressable.
+	if len(fields) > 1 &&
+		len(fields[0].index) == len(fields[1].index) &&
+		fields[0].tagged == fields[1].tagged {
+		return field{}, false
+	}
+
+	return fields[0], true
+}
+
+func indexLess(a, b []int) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex but returns an error
+// rather than panicking if it must step through a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	return walkFieldIndex(v, index, false)
+}
+
+// copyFieldByIndex is like fieldByIndex, but replaces the embedded pointers
+// it steps through with pointers to shallow copies, so that the field can
+// be set without modifying the structs they pointed to. v must be
+// addressable.
+func copyFieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	return walkFieldIndex(v, index, true)
+}
+
+func walkFieldIndex(v reflect.Value, index []int, copyPtrs bool) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, fmt.Errorf(
+					"%w: embedded struct %s is nil", ErrNotFound, v.Type().Elem())
+			}
+
+			if copyPtrs {
+				if !v.CanSet() {
+					v = unexportedEmbed(v)
+				}
+
+				v.Set(shallowCopy(v))
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, nil
+}
+
+// unexportedEmbed returns the addressable unexported embedded field v as a
+// value that can be set, so that a struct embedded by pointer can be
+// replaced with a copy of it. Its promoted fields are settable, so this
+// only allows copying what could be modified anyway.
+func unexportedEmbed(v reflect.Value) reflect.Value {
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
@@ -3,6 +3,7 @@ package pointerstructure
 import (
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -11,12 +12,43 @@ import (
 // For struct values a `pointer:"<name>"` tag on the struct's
 // fields may be used to override that field's name for lookup purposes.
 // Alternatively the tag name used can be overridden in the `Config`.
+//
+// Values implementing PointerGetter resolve their own children.
+//
+// Fields of embedded structs are promoted to the embedding struct using
+// the same rules as encoding/json, so "/Name" finds the Name field of an
+// embedded struct unless a shallower field has the same name.
 func (p *Pointer) Get(v interface{}) (interface{}, error) {
 	// fast-path the empty address case to avoid reflect.ValueOf below
 	if len(p.Parts) == 0 {
 		return v, nil
 	}
 
+	value, _, err := p.get(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface(), nil
+}
+
+// get is the implementation of Get. It returns the resulting reflect.Value,
+// which is addressable if it was reached through a pointer, so that it can
+// be modified in place. The value prior to applying the
+// ValueTransformationHook for the last part is returned as original.
+func (p *Pointer) get(v interface{}) (value, original reflect.Value, err error) {
+	return p.getFollowing(v, nil)
+}
+
+// getFollowing is get with the chain of $ref references that are already
+// being followed when Config.FollowRefs is set, to detect cycles.
+func (p *Pointer) getFollowing(v interface{}, chain []string) (value, original reflect.Value, err error) {
+	return p.getWithin(v, reflect.ValueOf(v), 0, chain)
+}
+
+// getWithin is getFollowing for the parts of p from index from, starting at
+// the value current that the earlier parts refer to in the document root.
+func (p *Pointer) getWithin(root interface{}, current reflect.Value, from int, chain []string) (value, original reflect.Value, err error) {
 	// Map for lookup of getter to call for type
 	funcMap := map[reflect.Kind]func(string, reflect.Value) (reflect.Value, error){
 		reflect.Array:  p.getSlice,
@@ -25,141 +57,218 @@ func (p *Pointer) Get(v interface{}) (interface{}, error) {
 		reflect.Struct: p.getStruct,
 	}
 
-	currentVal := reflect.ValueOf(v)
-	for i, part := range p.Parts {
-		for currentVal.Kind() == reflect.Interface {
-			currentVal = currentVal.Elem()
+	currentVal := current
+	original = currentVal
+	for i := from; i < len(p.Parts); i++ {
+		part := p.Parts[i]
+		if p.Config.DecodeRawJSON {
+			currentVal, err = decodeRawJSON(currentVal)
+			if err != nil {
+				return value, original, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
 		}
 
-		for currentVal.Kind() == reflect.Ptr {
-			currentVal = reflect.Indirect(currentVal)
+		if p.Config.FollowRefs {
+			currentVal, err = p.followRefs(root, currentVal, chain)
+			if err != nil {
+				return value, original, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
 		}
 
-		f, ok := funcMap[currentVal.Kind()]
-		if !ok {
-			return nil, fmt.Errorf(
-				"%s: at part %d, %w: %s", p, i, ErrInvalidKind, currentVal.Kind())
-		}
+		var r interface{}
+		currentVal, r = indirectResolver(currentVal, pointerGetterType)
 
-		var err error
-		currentVal, err = f(part, currentVal)
-		if err != nil {
-			return nil, fmt.Errorf("%s at part %d: %w", p, i, err)
+		// Types that resolve their own children take precedence
+		if getter, ok := r.(PointerGetter); ok {
+			child, err := getter.PointerGet(part)
+			if err != nil {
+				return value, original, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
+
+			currentVal = reflect.ValueOf(child)
+		} else {
+			f, ok := funcMap[currentVal.Kind()]
+			if !ok {
+				return value, original, fmt.Errorf(
+					"%s: at part %d, %w: %s", p, i, ErrInvalidKind, currentVal.Kind())
+			}
+
+			var err error
+			currentVal, err = f(part, currentVal)
+			if err != nil {
+				return value, original, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
 		}
+
+		original = currentVal
 		if p.Config.ValueTransformationHook != nil {
 			currentVal = p.Config.ValueTransformationHook(currentVal)
 			if currentVal == reflect.ValueOf(nil) {
-				return nil, fmt.Errorf("%s at part %d: ValueTransformationHook returned the value of a nil interface", p, i)
+				return value, original, fmt.Errorf("%s at part %d: ValueTransformationHook returned the value of a nil interface", p, i)
 			}
 		}
 	}
 
-	return currentVal.Interface(), nil
+	if p.Config.FollowRefs {
+		currentVal, err = p.followRefs(root, currentVal, chain)
+		if err != nil {
+			return value, original, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+
+	return currentVal, original, nil
 }
 
 func (p *Pointer) getMap(part string, m reflect.Value) (reflect.Value, error) {
 	var zeroValue reflect.Value
 
-	// Coerce the string part to the correct key type
-	key, err := coerce(reflect.ValueOf(part), m.Type().Key())
+	key, found, err := p.mapKey(part, m)
 	if err != nil {
 		return zeroValue, err
 	}
+	if !found {
+		return zeroValue, fmt.Errorf("%w %#v", ErrNotFound, key.Interface())
+	}
+
+	// Get the key
+	return m.MapIndex(key), nil
+}
+
+// mapKey returns the key in map m that part refers to. If no such key
+// exists, found is false and the part coerced to the key type is returned.
+func (p *Pointer) mapKey(part string, m reflect.Value) (key reflect.Value, found bool, err error) {
+	// Coerce the string part to the correct key type
+	key, err = p.Config.coerceKey(part, m.Type().Key())
+	if err != nil {
+		return key, false, err
+	}
 
 	// Verify that the key exists
-	found := false
 	for _, k := range m.MapKeys() {
 		if k.Interface() == key.Interface() {
-			found = true
-			break
+			return key, true, nil
 		}
 	}
-	if !found {
-		return zeroValue, fmt.Errorf("%w %#v", ErrNotFound, key.Interface())
+
+	// Try a non-exact match if it is enabled and the keys are strings
+	match := p.Config.keyMatcher()
+	if match == nil || m.Type().Key().Kind() != reflect.String {
+		return key, false, nil
 	}
 
-	// Get the key
-	return m.MapIndex(key), nil
+	var matches []reflect.Value
+	for _, k := range m.MapKeys() {
+		if match(k.String(), part) {
+			matches = append(matches, k)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return key, false, nil
+
+	case 1:
+		return matches[0], true, nil
+
+	default:
+		names := make([]string, len(matches))
+		for i, k := range matches {
+			names[i] = fmt.Sprintf("%q", k.String())
+		}
+		sort.Strings(names)
+
+		return key, false, fmt.Errorf(
+			"%w: %q matches keys %s", ErrAmbiguous, part, strings.Join(names, ", "))
+	}
 }
 
 func (p *Pointer) getSlice(part string, v reflect.Value) (reflect.Value, error) {
 	var zeroValue reflect.Value
 
-	// Coerce the key to an int
-	idxVal, err := coerce(reflect.ValueOf(part), reflect.TypeOf(42))
+	idx, err := p.sliceIndex(part, v.Len())
 	if err != nil {
 		return zeroValue, err
 	}
+
+	// Get the key
+	return v.Index(idx), nil
+}
+
+// sliceIndex returns the index that part refers to in a slice or array of
+// the given length.
+func (p *Pointer) sliceIndex(part string, length int) (int, error) {
+	// Coerce the key to an int
+	idxVal, err := p.Config.coerceKey(part, reflect.TypeOf(42))
+	if err != nil {
+		return 0, err
+	}
 	idx := int(idxVal.Int())
 
+	// Negative indexes count from the end if enabled
+	if idx < 0 && p.Config.NegativeIndex {
+		idx += length
+		if idx < 0 {
+			return 0, fmt.Errorf(
+				"index %d is %w (length = %d)", idx-length, ErrOutOfRange, length)
+		}
+	}
+
 	// Verify we're within bounds
-	if idx < 0 || idx >= v.Len() {
-		return zeroValue, fmt.Errorf(
-			"index %d is %w (length = %d)", idx, ErrOutOfRange, v.Len())
+	if idx < 0 || idx >= length {
+		return 0, fmt.Errorf(
+			"index %d is %w (length = %d)", idx, ErrOutOfRange, length)
 	}
 
-	// Get the key
-	return v.Index(idx), nil
+	return idx, nil
 }
 
 func (p *Pointer) getStruct(part string, m reflect.Value) (reflect.Value, error) {
-	var foundField reflect.Value
-	var found bool
-	var ignored bool
-	typ := m.Type()
+	fields, err := cachedStructFields(m.Type(), p.Config.tagName())
+	if err != nil {
+		return reflect.Value{}, err
+	}
 
-	tagName := p.Config.TagName
-	if tagName == "" {
-		tagName = "pointer"
+	f, err := p.structField(fields, part)
+	if err != nil {
+		return reflect.Value{}, err
 	}
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
+	return fieldByIndex(m, f.index)
+}
 
-		if field.PkgPath != "" {
-			// this is an unexported field so ignore it
-			continue
-		}
+// structField finds the field that part refers to.
+func (p *Pointer) structField(fields *structFields, part string) (field, error) {
+	if idx, ok := fields.byName[part]; ok {
+		return fields.list[idx], nil
+	}
 
-		fieldTag := field.Tag.Get(tagName)
+	if _, ok := fields.ignored[part]; ok {
+		return field{}, fmt.Errorf("struct field %q is ignored and cannot be used", part)
+	}
 
-		if fieldTag != "" {
-			if idx := strings.Index(fieldTag, ","); idx != -1 {
-				fieldTag = fieldTag[0:idx]
+	// Try a non-exact match if it is enabled
+	if match := p.Config.keyMatcher(); match != nil {
+		var matches []field
+		for _, f := range fields.list {
+			if match(f.name, part) {
+				matches = append(matches, f)
 			}
+		}
 
-			if strings.Contains(fieldTag, "|") {
-				// should this panic instead?
-				return foundField, fmt.Errorf("pointer struct tag cannot contain the '|' character")
+		switch len(matches) {
+		case 0:
+		case 1:
+			return matches[0], nil
+		default:
+			names := make([]string, len(matches))
+			for i, f := range matches {
+				names[i] = fmt.Sprintf("%q", f.name)
 			}
 
-			if fieldTag == "-" {
-				// we should ignore this field but cannot immediately return because its possible another
-				// field has a tag that would allow it to assume this ones name.
-
-				if field.Name == part {
-					found = true
-					ignored = true
-				}
-				continue
-			} else if fieldTag == part {
-				// we can go ahead and return now as the tag is enough to
-				// indicate that this is the correct field
-				return m.Field(i), nil
-			}
-		} else if field.Name == part {
-			foundField = m.Field(i)
-			found = true
+			return field{}, fmt.Errorf(
+				"%w: %q matches struct fields %s", ErrAmbiguous, part, strings.Join(names, ", "))
 		}
 	}
 
-	if !found {
-		return reflect.Value{}, fmt.Errorf("%w: struct field with name %q", ErrNotFound, part)
-	}
-
-	if ignored {
-		return reflect.Value{}, fmt.Errorf("struct field %q is ignored and cannot be used", part)
-	}
-
-	return foundField, nil
+	return field{}, fmt.Errorf("%w: struct field with name %q", ErrNotFound, part)
 }
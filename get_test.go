@@ -1,8 +1,10 @@
 package pointerstructure
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -162,9 +164,28 @@ func TestPointerGet_Hook(t *testing.T) {
 	}
 }
 
+type testMetadata struct {
+	Name string
+	Kind string
+}
+
+type testOtherMetadata struct {
+	Name string
+}
+
+type testTagged struct {
+	Label string `pointer:"Name"`
+}
+
+type testResource struct {
+	testMetadata
+	Kind string
+}
+
 func TestPointerGet(t *testing.T) {
 	type testStringType string
 	type testIntType int
+	type Metadata testMetadata
 
 	cases := []struct {
 		Name    string
@@ -352,6 +373,123 @@ func TestPointerGet(t *testing.T) {
 			false,
 		},
 
+		{
+			"struct embedded field",
+			[]string{"Name"},
+			"",
+			&testResource{testMetadata: testMetadata{Name: "foo"}},
+			"foo",
+			false,
+		},
+
+		{
+			"struct embedded by name",
+			[]string{"testMetadata", "Name"},
+			"",
+			&testResource{testMetadata: testMetadata{Name: "foo"}},
+			nil,
+			true,
+		},
+
+		{
+			"struct embedded shadowed",
+			[]string{"Kind"},
+			"",
+			&testResource{Kind: "outer", testMetadata: testMetadata{Kind: "inner"}},
+			"outer",
+			false,
+		},
+
+		{
+			"struct embedded tag wins at same depth",
+			[]string{"Name"},
+			"",
+			&struct {
+				testMetadata
+				testTagged
+			}{testMetadata{Name: "foo"}, testTagged{Label: "bar"}},
+			"bar",
+			false,
+		},
+
+		{
+			"struct embedded conflict",
+			[]string{"Name"},
+			"",
+			&struct {
+				testMetadata
+				testOtherMetadata
+			}{testMetadata{Name: "foo"}, testOtherMetadata{Name: "bar"}},
+			nil,
+			true,
+		},
+
+		{
+			"struct embedded with tag is not promoted",
+			[]string{"meta", "Name"},
+			"",
+			&struct {
+				Metadata `pointer:"meta"`
+			}{Metadata{Name: "foo"}},
+			"foo",
+			false,
+		},
+
+		{
+			"struct unexported embedded with tag is promoted",
+			[]string{"Name"},
+			"",
+			&struct {
+				testMetadata `pointer:"meta"`
+			}{testMetadata{Name: "foo"}},
+			"foo",
+			false,
+		},
+
+		{
+			"struct unexported embedded with tag by name",
+			[]string{"meta"},
+			"",
+			&struct {
+				testMetadata `pointer:"meta"`
+			}{testMetadata{Name: "foo"}},
+			nil,
+			true,
+		},
+
+		{
+			"struct embedded pointer",
+			[]string{"Name"},
+			"",
+			&struct {
+				*testMetadata
+			}{&testMetadata{Name: "foo"}},
+			"foo",
+			false,
+		},
+
+		{
+			"struct embedded nil pointer",
+			[]string{"Name"},
+			"",
+			&struct {
+				*testMetadata
+			}{},
+			nil,
+			true,
+		},
+
+		{
+			"struct embedded deep",
+			[]string{"Name"},
+			"",
+			&struct {
+				testResource
+			}{testResource{testMetadata: testMetadata{Name: "foo"}}},
+			"foo",
+			false,
+		},
+
 		{
 			"struct tag invalid",
 			[]string{"synthetic|name"},
@@ -381,3 +519,151 @@ func TestPointerGet(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerGet_keyMatching(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Parts  []string
+		Config Config
+		Input  interface{}
+		Output interface{}
+		Err    error
+	}{
+		{
+			Name:   "exact only by default",
+			Parts:  []string{"server", "port"},
+			Input:  map[string]interface{}{"SERVER": map[string]interface{}{"port": 80}},
+			Output: nil,
+			Err:    ErrNotFound,
+		},
+		{
+			Name:   "case insensitive map",
+			Parts:  []string{"server", "port"},
+			Config: Config{CaseInsensitive: true},
+			Input:  map[string]interface{}{"SERVER": map[string]interface{}{"Port": 80}},
+			Output: 80,
+		},
+		{
+			Name:   "case insensitive struct",
+			Parts:  []string{"server", "port"},
+			Config: Config{CaseInsensitive: true},
+			Input: &struct {
+				Server struct{ Port int }
+			}{},
+			Output: 0,
+		},
+		{
+			Name:   "exact match wins",
+			Parts:  []string{"port"},
+			Config: Config{CaseInsensitive: true},
+			Input:  map[string]interface{}{"Port": 1, "port": 2, "PORT": 3},
+			Output: 2,
+		},
+		{
+			Name:   "exact struct match wins",
+			Parts:  []string{"port"},
+			Config: Config{CaseInsensitive: true},
+			Input: &struct {
+				Port  int
+				Other int `pointer:"port"`
+			}{Port: 1, Other: 2},
+			Output: 2,
+		},
+		{
+			Name:   "ambiguous map",
+			Parts:  []string{"port"},
+			Config: Config{CaseInsensitive: true},
+			Input:  map[string]interface{}{"Port": 1, "PORT": 3},
+			Err:    ErrAmbiguous,
+		},
+		{
+			Name:   "ambiguous struct",
+			Parts:  []string{"port"},
+			Config: Config{CaseInsensitive: true},
+			Input: &struct {
+				Port int
+				PORT int
+			}{},
+			Err: ErrAmbiguous,
+		},
+		{
+			Name:  "normalizer",
+			Parts: []string{"server_port"},
+			Config: Config{KeyNormalizer: func(s string) string {
+				return strings.ToLower(strings.Replace(s, "_", "", -1))
+			}},
+			Input:  &struct{ ServerPort int }{ServerPort: 80},
+			Output: 80,
+		},
+		{
+			Name:   "non-string keys are exact",
+			Parts:  []string{"42"},
+			Config: Config{CaseInsensitive: true},
+			Input:  map[int]interface{}{42: "foo"},
+			Output: "foo",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			p := &Pointer{Parts: tc.Parts, Config: tc.Config}
+			actual, err := p.Get(tc.Input)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
+
+func TestPointerGet_negativeIndex(t *testing.T) {
+	doc := []interface{}{1, 2, 3}
+
+	cases := []struct {
+		Pointer string
+		Output  interface{}
+		Err     error
+	}{
+		{"/0", 1, nil},
+		{"/-1", 3, nil},
+		{"/-3", 1, nil},
+		{"/-4", nil, ErrOutOfRange},
+		{"/3", nil, ErrOutOfRange},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.NegativeIndex = true
+			if p.String() != tc.Pointer {
+				t.Fatalf("bad string: %s", p)
+			}
+
+			actual, err := p.Get(doc)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected %v, got: %v", tc.Err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if actual != tc.Output {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+
+	// Negative indexes are out of range by default
+	if _, err := Get(doc, "/-1"); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("expected ErrOutOfRange, got: %v", err)
+	}
+}
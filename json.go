@@ -0,0 +1,324 @@
+package pointerstructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// GetJSON reads the value at the pointer p from the JSON document read
+// from r without decoding the whole document. Values that are not on the
+// path of the pointer are skipped, and reading stops as soon as the value
+// is found, so this is efficient for extracting a value from a large
+// document.
+//
+// Errors are reported the same as Get on the decoded document, for example
+// an error wrapping ErrNotFound is returned for a missing object key and
+// ErrOutOfRange for an array index past the end. If an object has duplicate
+// keys, the first is used, like the other JSON functions of this package.
+//
+// With Config.NegativeIndex, an array indexed from the end is read to its
+// end to find its length, keeping only the elements that may be needed.
+func GetJSON(r io.Reader, p *Pointer) (json.RawMessage, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	for i, part := range p.Parts {
+		var err error
+		dec, err = p.seekJSON(dec, part)
+		if err != nil {
+			return nil, fmt.Errorf("%s at part %d: %w", p, i, err)
+		}
+	}
+
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	return raw, nil
+}
+
+// seekJSON advances dec to the start of the child value with the given part
+// in the value at the current position. The child may have to be read from
+// a new decoder, which is returned.
+func (p *Pointer) seekJSON(dec *json.Decoder, part string) (*json.Decoder, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			if key == part {
+				return dec, nil
+			}
+
+			if err := skipJSON(dec); err != nil {
+				return nil, err
+			}
+		}
+
+		return nil, fmt.Errorf("%w %#v", ErrNotFound, part)
+
+	case json.Delim('['):
+		idxVal, err := p.Config.coerceKey(part, reflect.TypeOf(42))
+		if err != nil {
+			return nil, err
+		}
+		idx := int(idxVal.Int())
+
+		if idx < 0 && p.Config.NegativeIndex {
+			return p.seekJSONFromEnd(dec, part, idx)
+		}
+
+		length := 0
+		for ; dec.More(); length++ {
+			if length == idx {
+				return dec, nil
+			}
+
+			if err := skipJSON(dec); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = p.sliceIndex(part, length)
+		return nil, err
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKind, jsonKind(tok))
+	}
+}
+
+// seekJSONFromEnd is seekJSON for an array and the negative index idx,
+// which counts from the end. The array is read to its end, keeping the
+// last -idx elements, and a decoder for the element is returned.
+func (p *Pointer) seekJSONFromEnd(dec *json.Decoder, part string, idx int) (*json.Decoder, error) {
+	var last []json.RawMessage
+	length := 0
+	for ; dec.More(); length++ {
+		// Only the last -idx elements can be the element. Comparing
+		// with the length rather than negating idx avoids overflowing
+		// for the smallest int.
+		if len(last) > 0 && len(last)+idx >= 0 {
+			last = last[1:]
+		}
+
+		var elem json.RawMessage
+		if err := dec.Decode(&elem); err != nil {
+			return nil, err
+		}
+		last = append(last, elem)
+	}
+
+	// Read the end of the array
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	if _, err := p.sliceIndex(part, length); err != nil {
+		return nil, err
+	}
+
+	sub := json.NewDecoder(bytes.NewReader(last[0]))
+	sub.UseNumber()
+	return sub, nil
+}
+
+// skipJSON skips the value at the current position of dec.
+func skipJSON(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonKind returns the kind that a scalar JSON token decodes to, to match
+// the errors returned by Get.
+func jsonKind(tok json.Token) reflect.Kind {
+	switch tok.(type) {
+	case json.Number:
+		return reflect.Float64
+	default:
+		return reflect.ValueOf(tok).Kind()
+	}
+}
+
+// ExtractJSON reads the values at all the given pointers from the JSON
+// document read from r in a single pass. Like GetJSON, subtrees that no
+// pointer refers to are skipped, and reading stops as soon as all values
+// are found.
+//
+// The result maps the string form of each pointer to its value. Pointers
+// that don't refer to a value in the document are not in the result. Like
+// GetJSON, if an object has duplicate keys the first is used.
+func ExtractJSON(r io.Reader, ptrs []*Pointer) (map[string]json.RawMessage, error) {
+	set := NewPointerSet(ptrs...)
+	e := &jsonExtractor{
+		result:    map[string]json.RawMessage{},
+		remaining: set.Len(),
+	}
+	if e.remaining == 0 {
+		return e.result, nil
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := e.extract(dec, &set.root, nil, false); err != nil {
+		return nil, err
+	}
+
+	return e.result, nil
+}
+
+// jsonExtractor is the state of ExtractJSON.
+type jsonExtractor struct {
+	result    map[string]json.RawMessage
+	remaining int
+}
+
+// extract reads the value at the current position of dec, which is at the
+// trie node n with the given parts, and stores the values of any pointers
+// within it. If self is true, the value for n itself is already stored.
+func (e *jsonExtractor) extract(dec *json.Decoder, n *pointerNode, parts []string, self bool) error {
+	if n.present && !self {
+		p := &Pointer{Parts: parts}
+		key := p.String()
+		if _, ok := e.result[key]; ok {
+			// This is a duplicate object key, the first value is used
+			return skipJSON(dec)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		e.result[key] = raw
+		e.remaining--
+		if len(n.children) == 0 || e.remaining == 0 {
+			return nil
+		}
+
+		// Pointers below this one are read from the value we just read
+		sub := json.NewDecoder(bytes.NewReader(raw))
+		sub.UseNumber()
+		return e.extract(sub, n, parts, true)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() && e.remaining > 0 {
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			child, ok := n.children[key.(string)]
+			if !ok {
+				if err := skipJSON(dec); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := e.extract(dec, child, append(parts, key.(string)), false); err != nil {
+				return err
+			}
+		}
+
+		return e.endJSON(dec)
+
+	case json.Delim('['):
+		// Children are keyed by part, so find the index each part refers to.
+		// Parts that aren't indexes can't match anything.
+		var c Config
+		byIndex := map[int][]string{}
+		for part := range n.children {
+			idxVal, err := c.coerceKey(part, reflect.TypeOf(42))
+			if err != nil {
+				continue
+			}
+
+			idx := int(idxVal.Int())
+			byIndex[idx] = append(byIndex[idx], part)
+		}
+
+		for idx := 0; dec.More() && e.remaining > 0; idx++ {
+			matches := byIndex[idx]
+			if len(matches) == 0 {
+				if err := skipJSON(dec); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if len(matches) == 1 {
+				err := e.extract(dec, n.children[matches[0]], append(parts, matches[0]), false)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Multiple parts can refer to the same index, such as "1"
+			// and "01", so read the value once and extract from it for each.
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return err
+			}
+
+			for _, part := range matches {
+				sub := json.NewDecoder(bytes.NewReader(raw))
+				sub.UseNumber()
+				err := e.extract(sub, n.children[part], append(parts, part), false)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		return e.endJSON(dec)
+	}
+
+	return nil
+}
+
+// endJSON reads the end of the object or array at the current position of
+// dec, unless all values are found and reading has stopped.
+func (e *jsonExtractor) endJSON(dec *json.Decoder) error {
+	if e.remaining == 0 {
+		return nil
+	}
+
+	_, err := dec.Token()
+	return err
+}
@@ -0,0 +1,251 @@
+package pointerstructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testJSON = `{
+	"alice": 42,
+	"bob": [
+		{"name": "Bob", "tags": ["a", "b"]},
+		{"name": "Robert", "nested": {"x": null}}
+	],
+	"big": 123456789012345678901234567890,
+	"a/b": {"m~n": true}
+}`
+
+func TestGetJSON(t *testing.T) {
+	cases := []struct {
+		Pointer string
+		Output  string
+		Err     error
+	}{
+		{"", "", nil},
+		{"/alice", "42", nil},
+		{"/bob/0/name", `"Bob"`, nil},
+		{"/bob/0/tags", `["a", "b"]`, nil},
+		{"/bob/1/nested/x", "null", nil},
+		{"/big", "123456789012345678901234567890", nil},
+		{"/a~1b/m~0n", "true", nil},
+		{"/nope", "", ErrNotFound},
+		{"/bob/1/nope", "", ErrNotFound},
+		{"/bob/2", "", ErrOutOfRange},
+		{"/bob/-1", "", ErrOutOfRange},
+		{"/bob/-", "", ErrConvert},
+		{"/alice/0", "", ErrInvalidKind},
+		{"/bob/1/nested/x/y", "", ErrInvalidKind},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			actual, err := GetJSON(strings.NewReader(testJSON), MustParse(tc.Pointer))
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+
+				// The error must match Get on the decoded document
+				var doc interface{}
+				if err := json.Unmarshal([]byte(testJSON), &doc); err != nil {
+					t.Fatalf("err: %s", err)
+				}
+				if _, err := Get(doc, tc.Pointer); !errors.Is(err, tc.Err) {
+					t.Fatalf("Get returned a different error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			expected := tc.Output
+			if tc.Pointer == "" {
+				expected = testJSON
+			}
+			if string(actual) != expected {
+				t.Fatalf("bad: %s", actual)
+			}
+		})
+	}
+}
+
+func TestGetJSON_stopsEarly(t *testing.T) {
+	// Everything after the value is invalid, but it is never read
+	for _, input := range []string{
+		`{"a": {"b": 1}, "c": ` + strings.Repeat("[", 1000),
+		`[{"a": [1, {"b": 1}]}, ` + strings.Repeat("[", 1000),
+	} {
+		p := MustParse("/a/b")
+		if input[0] == '[' {
+			p = MustParse("/0/a/1/b")
+		}
+
+		actual, err := GetJSON(strings.NewReader(input), p)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if string(actual) != "1" {
+			t.Fatalf("bad: %s", actual)
+		}
+	}
+}
+
+func TestGetJSON_duplicateKey(t *testing.T) {
+	// The first key is used, like the other JSON functions
+	doc := []byte(`{"a": 1, "a": 2}`)
+	p := MustParse("/a")
+
+	actual, err := GetJSON(bytes.NewReader(doc), p)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(actual) != "1" {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	extracted, err := ExtractJSON(bytes.NewReader(doc), []*Pointer{p})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(extracted["/a"]) != "1" {
+		t.Fatalf("bad: %s", extracted["/a"])
+	}
+
+	// A value set with SetJSON is the value read back
+	doc, err = SetJSON(doc, p, 3)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	actual, err = GetJSON(bytes.NewReader(doc), p)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(actual) != "3" {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestGetJSON_negativeIndex(t *testing.T) {
+	cases := []struct {
+		Pointer string
+		Output  string
+		Err     error
+	}{
+		{"/bob/-1/name", `"Robert"`, nil},
+		{"/bob/-2/tags/-1", `"b"`, nil},
+		{"/bob/-3", "", ErrOutOfRange},
+		{"/bob/-9223372036854775808", "", ErrOutOfRange},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Pointer, func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.NegativeIndex = true
+
+			actual, err := GetJSON(strings.NewReader(testJSON), p)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if string(actual) != tc.Output {
+				t.Fatalf("bad: %s", actual)
+			}
+		})
+	}
+}
+
+func TestGetJSON_syntaxError(t *testing.T) {
+	_, err := GetJSON(strings.NewReader(`{"a": [1,}`), MustParse("/b"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	var ps []*Pointer
+	for _, raw := range []string{
+		"/alice",
+		"/bob/0/name",
+		"/bob/1",
+		"/bob/1/nested/x",
+		"/bob/01/name",
+		"/bob/5",
+		"/nope",
+		"/a~1b/m~0n",
+	} {
+		ps = append(ps, MustParse(raw))
+	}
+
+	actual, err := ExtractJSON(strings.NewReader(testJSON), ps)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]string{
+		"/alice":          "42",
+		"/bob/0/name":     `"Bob"`,
+		"/bob/1":          `{"name": "Robert", "nested": {"x": null}}`,
+		"/bob/1/nested/x": "null",
+		"/bob/01/name":    `"Robert"`,
+		"/a~1b/m~0n":      "true",
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	for k, v := range expected {
+		if string(actual[k]) != v {
+			t.Fatalf("bad %s: %s", k, actual[k])
+		}
+	}
+}
+
+func TestExtractJSON_root(t *testing.T) {
+	actual, err := ExtractJSON(strings.NewReader(`{"a": [1, 2]}`), []*Pointer{
+		MustParse(""),
+		MustParse("/a/1"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(actual[""]) != `{"a": [1, 2]}` || string(actual["/a/1"]) != "2" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestExtractJSON_stopsEarly(t *testing.T) {
+	r := strings.NewReader(`{"a": 1, "b": {"c": 2}, "d": ` + strings.Repeat("[", 1000))
+	actual, err := ExtractJSON(r, []*Pointer{MustParse("/a"), MustParse("/b/c")})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(actual["/a"]) != "1" || string(actual["/b/c"]) != "2" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestExtractJSON_duplicateKey(t *testing.T) {
+	actual, err := ExtractJSON(strings.NewReader(`{"a": 1, "b": 2, "a": {"x": 3}, "c": 4}`), []*Pointer{
+		MustParse("/a"),
+		MustParse("/c"),
+		MustParse("/d"),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(actual) != 2 || string(actual["/a"]) != "1" || string(actual["/c"]) != "4" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
@@ -0,0 +1,399 @@
+package pointerstructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// SetJSON sets the value at the pointer p in the encoded JSON document doc
+// and returns the modified document. Only the bytes of the value being
+// replaced are changed, so the order of keys, whitespace, and formatting
+// of numbers elsewhere in the document are preserved exactly. The value
+// is encoded with encoding/json.
+//
+// Like Set, the parent of the pointer must exist, and "-" appends to an
+// array. New object keys are added after the last existing key. If an
+// object has duplicate keys, the first is used.
+func SetJSON(doc []byte, p *Pointer, value interface{}) ([]byte, error) {
+	encoded, err := encodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("set %s: %w", p, err)
+	}
+
+	// if we represent the root doc, return that
+	if p.IsRoot() {
+		return encoded, nil
+	}
+
+	c, err := p.parentJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	part := p.Parts[len(p.Parts)-1]
+	if c.object {
+		if idx := c.find(doc, part); idx >= 0 {
+			return spliceJSON(doc, c.members[idx].value, encoded), nil
+		}
+
+		key, err := encodeJSON(part)
+		if err != nil {
+			return nil, fmt.Errorf("set %s: %w", p, err)
+		}
+
+		member := append(append(key, ':'), encoded...)
+		return c.insert(doc, member), nil
+	}
+
+	// If the part is the special "-", that means to append it (RFC6901 4.)
+	if part == "-" {
+		return c.insert(doc, encoded), nil
+	}
+
+	idx, err := p.indexJSON(c, part)
+	if err != nil {
+		return nil, fmt.Errorf("set %s: %w", p, err)
+	}
+
+	return spliceJSON(doc, c.members[idx].value, encoded), nil
+}
+
+// DeleteJSON deletes the value at the pointer p from the encoded JSON
+// document doc and returns the modified document. Like SetJSON, the rest
+// of the document is preserved exactly.
+//
+// Like Delete, deleting an array element shifts the following elements,
+// and deleting an object key that doesn't exist does nothing. Deleting the
+// root results in the document "null".
+func DeleteJSON(doc []byte, p *Pointer) ([]byte, error) {
+	// if we represent the root doc, we've deleted everything
+	if p.IsRoot() {
+		return []byte("null"), nil
+	}
+
+	c, err := p.parentJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	part := p.Parts[len(p.Parts)-1]
+	var idx int
+	if c.object {
+		idx = c.find(doc, part)
+		if idx < 0 {
+			return spliceJSON(doc, jsonSpan{}, nil), nil
+		}
+	} else {
+		idx, err = p.indexJSON(c, part)
+		if err != nil {
+			return nil, fmt.Errorf("delete %s: %w", p, err)
+		}
+	}
+
+	// Remove the separating comma along with the member
+	m := c.members[idx]
+	var remove jsonSpan
+	switch {
+	case idx < len(c.members)-1:
+		remove = jsonSpan{m.start(), c.members[idx+1].start()}
+	case idx > 0:
+		remove = jsonSpan{c.members[idx-1].value.end, m.value.end}
+	default:
+		remove = jsonSpan{m.start(), m.value.end}
+	}
+
+	return spliceJSON(doc, remove, nil), nil
+}
+
+// jsonSpan is the range of bytes [start, end) in a document.
+type jsonSpan struct {
+	start, end int
+}
+
+// jsonMember is a member of a JSON object or an element of a JSON array,
+// in which case the key is empty.
+type jsonMember struct {
+	key   jsonSpan
+	value jsonSpan
+}
+
+func (m jsonMember) start() int {
+	if m.key.end > 0 {
+		return m.key.start
+	}
+
+	return m.value.start
+}
+
+// jsonContainer is a scanned JSON object or array.
+type jsonContainer struct {
+	object  bool
+	members []jsonMember
+
+	// end is the offset of the closing delimiter.
+	end int
+}
+
+// find returns the index of the first member with the key part, or -1.
+func (c *jsonContainer) find(doc []byte, part string) int {
+	for i, m := range c.members {
+		if jsonKeyEquals(doc[m.key.start:m.key.end], part) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// insert adds a member or element after the existing ones.
+func (c *jsonContainer) insert(doc, member []byte) []byte {
+	if len(c.members) == 0 {
+		return spliceJSON(doc, jsonSpan{c.end, c.end}, member)
+	}
+
+	end := c.members[len(c.members)-1].value.end
+	return spliceJSON(doc, jsonSpan{end, end}, append([]byte{','}, member...))
+}
+
+// indexJSON returns the index of the array element that part refers to.
+func (p *Pointer) indexJSON(c *jsonContainer, part string) (int, error) {
+	return p.sliceIndex(part, len(c.members))
+}
+
+// parentJSON finds and scans the object or array that is the parent of
+// the value p points to.
+func (p *Pointer) parentJSON(doc []byte) (*jsonContainer, error) {
+	parent := p.Parent()
+	span, err := parent.findJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := scanJSONContainer(doc, span.start)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+
+	return c, nil
+}
+
+// findJSON returns the span of the value that p points to in doc.
+func (p *Pointer) findJSON(doc []byte) (jsonSpan, error) {
+	start := skipJSONSpace(doc, 0)
+	end, err := scanJSONValue(doc, start)
+	if err != nil {
+		return jsonSpan{}, fmt.Errorf("%s: %w", p, err)
+	}
+
+	current := jsonSpan{start, end}
+	for i, part := range p.Parts {
+		c, err := scanJSONContainer(doc, current.start)
+		if err != nil {
+			return jsonSpan{}, fmt.Errorf("%s at part %d: %w", p, i, err)
+		}
+
+		if c.object {
+			idx := c.find(doc, part)
+			if idx < 0 {
+				return jsonSpan{}, fmt.Errorf(
+					"%s at part %d: %w %#v", p, i, ErrNotFound, part)
+			}
+
+			current = c.members[idx].value
+			continue
+		}
+
+		idx, err := p.indexJSON(c, part)
+		if err != nil {
+			return jsonSpan{}, fmt.Errorf("%s at part %d: %w", p, i, err)
+		}
+
+		current = c.members[idx].value
+	}
+
+	return current, nil
+}
+
+// scanJSONContainer scans the object or array starting at offset i.
+// If the value at i is not an object or array, an error wrapping
+// ErrInvalidKind is returned.
+func scanJSONContainer(doc []byte, i int) (*jsonContainer, error) {
+	var closing byte
+	switch doc[i] {
+	case '{':
+		closing = '}'
+	case '[':
+		closing = ']'
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidKind, jsonScalarKind(doc[i]))
+	}
+
+	c := &jsonContainer{object: closing == '}'}
+	j := skipJSONSpace(doc, i+1)
+	if j < len(doc) && doc[j] == closing {
+		c.end = j
+		return c, nil
+	}
+
+	for {
+		var m jsonMember
+		if c.object {
+			if j >= len(doc) || doc[j] != '"' {
+				return nil, jsonSyntaxError(j)
+			}
+
+			end, err := scanJSONValue(doc, j)
+			if err != nil {
+				return nil, err
+			}
+			m.key = jsonSpan{j, end}
+
+			j = skipJSONSpace(doc, end)
+			if j >= len(doc) || doc[j] != ':' {
+				return nil, jsonSyntaxError(j)
+			}
+			j = skipJSONSpace(doc, j+1)
+		}
+
+		end, err := scanJSONValue(doc, j)
+		if err != nil {
+			return nil, err
+		}
+		m.value = jsonSpan{j, end}
+		c.members = append(c.members, m)
+
+		j = skipJSONSpace(doc, end)
+		if j >= len(doc) {
+			return nil, jsonSyntaxError(j)
+		}
+
+		switch doc[j] {
+		case ',':
+			j = skipJSONSpace(doc, j+1)
+
+		case closing:
+			c.end = j
+			return c, nil
+
+		default:
+			return nil, jsonSyntaxError(j)
+		}
+	}
+}
+
+// scanJSONValue returns the offset just past the end of the value that
+// starts at offset i.
+func scanJSONValue(doc []byte, i int) (int, error) {
+	if i >= len(doc) {
+		return i, jsonSyntaxError(i)
+	}
+
+	switch c := doc[i]; {
+	case c == '{' || c == '[':
+		container, err := scanJSONContainer(doc, i)
+		if err != nil {
+			return i, err
+		}
+
+		return container.end + 1, nil
+
+	case c == '"':
+		for j := i + 1; j < len(doc); j++ {
+			switch doc[j] {
+			case '\\':
+				j++
+			case '"':
+				return j + 1, nil
+			}
+		}
+
+		return len(doc), jsonSyntaxError(len(doc))
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		j := i + 1
+		for j < len(doc) && bytes.IndexByte([]byte("+-0123456789.eE"), doc[j]) >= 0 {
+			j++
+		}
+
+		return j, nil
+
+	default:
+		for _, lit := range []string{"true", "false", "null"} {
+			if bytes.HasPrefix(doc[i:], []byte(lit)) {
+				return i + len(lit), nil
+			}
+		}
+
+		return i, jsonSyntaxError(i)
+	}
+}
+
+func skipJSONSpace(doc []byte, i int) int {
+	for i < len(doc) {
+		switch doc[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+// jsonKeyEquals returns true if the encoded JSON string key is part.
+func jsonKeyEquals(key []byte, part string) bool {
+	// Fast-path keys without escapes
+	if bytes.IndexByte(key, '\\') < 0 {
+		return string(key[1:len(key)-1]) == part
+	}
+
+	var decoded string
+	if err := json.Unmarshal(key, &decoded); err != nil {
+		return false
+	}
+
+	return decoded == part
+}
+
+// jsonScalarKind returns the kind that the scalar JSON value starting with
+// c decodes to, to match the errors returned by Get.
+func jsonScalarKind(c byte) reflect.Kind {
+	switch {
+	case c == '"':
+		return reflect.String
+	case c == 't' || c == 'f':
+		return reflect.Bool
+	case c == 'n':
+		return reflect.Invalid
+	default:
+		return reflect.Float64
+	}
+}
+
+func jsonSyntaxError(offset int) error {
+	return fmt.Errorf("invalid JSON at offset %d", offset)
+}
+
+// encodeJSON encodes v without escaping HTML characters or adding a
+// trailing newline.
+func encodeJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// spliceJSON returns a copy of doc with the span replaced by value.
+func spliceJSON(doc []byte, span jsonSpan, value []byte) []byte {
+	result := make([]byte, 0, len(doc)-(span.end-span.start)+len(value))
+	result = append(result, doc[:span.start]...)
+	result = append(result, value...)
+	return append(result, doc[span.end:]...)
+}
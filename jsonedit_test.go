@@ -0,0 +1,236 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const testEditJSON = `{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`
+
+func TestSetJSON(t *testing.T) {
+	cases := []struct {
+		Pointer string
+		Value   interface{}
+		Output  string
+		Err     error
+	}{
+		{
+			"/name",
+			"bar",
+			`{
+  "name": "bar",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/spec/ports/1",
+			8443,
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 8443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/spec/ports/-",
+			"<x>",
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443,"<x>"]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/list/-",
+			map[string]interface{}{"a": true},
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [{"a":true}],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/empty/new",
+			nil,
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {"new":null},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/new",
+			1,
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1,"new":1
+}`,
+			nil,
+		},
+
+		{
+			"/a~1b",
+			2,
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [],
+  "a/b": 2
+}`,
+			nil,
+		},
+
+		{"", 42, "42", nil},
+		{"/nope/a", 1, "", ErrNotFound},
+		{"/spec/ports/2", 1, "", ErrOutOfRange},
+		{"/spec/ports/x", 1, "", ErrConvert},
+		{"/name/a", 1, "", ErrInvalidKind},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			actual, err := SetJSON([]byte(testEditJSON), MustParse(tc.Pointer), tc.Value)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if string(actual) != tc.Output {
+				t.Fatalf("bad:\n%s", actual)
+			}
+		})
+	}
+}
+
+func TestDeleteJSON(t *testing.T) {
+	cases := []struct {
+		Pointer string
+		Output  string
+		Err     error
+	}{
+		{
+			"/name",
+			`{
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/a~1b",
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80, 443]},
+  "empty": {},
+  "list": []
+}`,
+			nil,
+		},
+
+		{
+			"/spec/ports/0",
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [443]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{
+			"/spec/ports/1",
+			`{
+  "name": "foo",
+  "spec": {"replicas": 1.50, "ports": [80]},
+  "empty": {},
+  "list": [],
+  "a/b": 1
+}`,
+			nil,
+		},
+
+		{"/nope", testEditJSON, nil},
+		{"", "null", nil},
+		{"/list/0", "", ErrOutOfRange},
+		{"/nope/a", "", ErrNotFound},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			actual, err := DeleteJSON([]byte(testEditJSON), MustParse(tc.Pointer))
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if string(actual) != tc.Output {
+				t.Fatalf("bad:\n%s", actual)
+			}
+		})
+	}
+}
+
+func TestDeleteJSON_onlyMember(t *testing.T) {
+	actual, err := DeleteJSON([]byte(`{"a": [ 1 ], "b": 2}`), MustParse("/a/0"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(actual) != `{"a": [  ], "b": 2}` {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestSetJSON_invalid(t *testing.T) {
+	for _, doc := range []string{``, `{"a": }`, `{"a": 1`, `[1 2]`, `{"a" 1}`} {
+		if _, err := SetJSON([]byte(doc), MustParse("/a"), 1); err == nil {
+			t.Fatalf("expected error for %q", doc)
+		}
+	}
+}
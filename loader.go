@@ -0,0 +1,76 @@
+package pointerstructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// Loader loads the document with the given URI. It is used by ResolveRefs
+// to resolve external references.
+type Loader interface {
+	Load(uri string) (interface{}, error)
+}
+
+// LoaderFunc is a function that implements Loader.
+type LoaderFunc func(uri string) (interface{}, error)
+
+func (f LoaderFunc) Load(uri string) (interface{}, error) { return f(uri) }
+
+// DecodeFn decodes the contents of a loaded document.
+type DecodeFn func([]byte) (interface{}, error)
+
+// MapLoader is a Loader of in-memory documents keyed by URI, which is
+// useful for tests.
+type MapLoader map[string]interface{}
+
+func (m MapLoader) Load(uri string) (interface{}, error) {
+	doc, ok := m[uri]
+	if !ok {
+		return nil, fmt.Errorf("%w document %q", ErrNotFound, uri)
+	}
+
+	return doc, nil
+}
+
+// FileLoader is a Loader that reads documents from the local filesystem.
+// The URIs may be file paths or "file://" URLs.
+type FileLoader struct {
+	// Decode decodes the file contents. This defaults to decoding JSON.
+	Decode DecodeFn
+}
+
+func (l FileLoader) Load(uri string) (interface{}, error) {
+	path := uri
+	if strings.HasPrefix(uri, "file://") {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return nil, err
+		}
+
+		path = u.Path
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeLoaded(l.Decode, data)
+}
+
+// decodeLoaded decodes the data with decode, or as JSON if it is nil.
+func decodeLoaded(decode DecodeFn, data []byte) (interface{}, error) {
+	if decode != nil {
+		return decode(data)
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,34 @@
+//go:build go1.16
+// +build go1.16
+
+package pointerstructure
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FSLoader is a Loader that reads documents from an fs.FS. URIs are
+// cleaned and a leading "/" is removed to form the path within the FS.
+type FSLoader struct {
+	FS fs.FS
+
+	// Decode decodes the file contents. This defaults to decoding JSON.
+	Decode DecodeFn
+}
+
+func (l FSLoader) Load(uri string) (interface{}, error) {
+	name := strings.TrimPrefix(path.Clean("/"+uri), "/")
+	if !fs.ValidPath(name) {
+		return nil, fmt.Errorf("invalid path %q", uri)
+	}
+
+	data, err := fs.ReadFile(l.FS, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeLoaded(l.Decode, data)
+}
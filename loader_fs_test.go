@@ -0,0 +1,36 @@
+//go:build go1.16
+// +build go1.16
+
+package pointerstructure
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFSLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"specs/common.json": &fstest.MapFile{Data: []byte(`{"Error": {"type": "object"}}`)},
+	}
+
+	input := map[string]interface{}{
+		"a": map[string]interface{}{"$ref": "common.json#/Error"},
+		"b": map[string]interface{}{"$ref": "/specs/common.json#/Error/type"},
+	}
+	actual, err := ResolveRefs(input, RefOptions{
+		Loader:  FSLoader{FS: fsys},
+		BaseURI: "specs/api.json",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"type": "object"},
+		"b": "object",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
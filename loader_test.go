@@ -0,0 +1,55 @@
+package pointerstructure
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileLoader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pointerstructure")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "common.json")
+	if err := ioutil.WriteFile(path, []byte(`{"Error": {"type": "object"}}`), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	input := map[string]interface{}{
+		"a": map[string]interface{}{"$ref": "common.json#/Error"},
+	}
+	actual, err := ResolveRefs(input, RefOptions{
+		Loader:  FileLoader{},
+		BaseURI: filepath.ToSlash(filepath.Join(dir, "api.json")),
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"type": "object"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// Custom decoding
+	doc, err := FileLoader{
+		Decode: func([]byte) (interface{}, error) { return "decoded", nil },
+	}.Load("file://" + filepath.ToSlash(path))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if doc != "decoded" {
+		t.Fatalf("bad: %#v", doc)
+	}
+
+	if _, err := (FileLoader{}).Load(filepath.Join(dir, "nope.json")); err == nil {
+		t.Fatal("expected error")
+	}
+}
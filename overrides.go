@@ -0,0 +1,109 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Override is a value to set at a pointer, typically from a command line
+// flag or an environment variable. The value is a string that Set coerces
+// to the type it is written to.
+type Override struct {
+	Pointer *Pointer
+	Value   string
+}
+
+// ParseOverride parses an override in the form "pointer=value", such as
+// "/server/port=8080". The pointer ends at the first "=", so the value may
+// contain "=" but the pointer can't.
+func ParseOverride(s string) (Override, error) {
+	idx := strings.IndexByte(s, '=')
+	if idx < 0 {
+		return Override{}, fmt.Errorf("override %q must be in the form pointer=value", s)
+	}
+
+	p, err := Parse(s[:idx])
+	if err != nil {
+		return Override{}, fmt.Errorf("override %q: %w", s, err)
+	}
+
+	return Override{Pointer: p, Value: s[idx+1:]}, nil
+}
+
+// EnvOverrides returns the overrides for the environment variables in
+// environ, in the "key=value" form returned by os.Environ, whose names
+// start with prefix. The rest of the name is split by sep into the parts
+// of the pointer. For example, with the prefix "APP__" and separator "__",
+// "APP__server__port=8080" sets "/server/port" to "8080".
+//
+// Environment variable names are often upper case, so set
+// Config.CaseInsensitive on the pointers of the overrides to match
+// lower case names.
+func EnvOverrides(environ []string, prefix, sep string) []Override {
+	var result []Override
+	for _, kv := range environ {
+		idx := strings.IndexByte(kv, '=')
+		if idx < 0 || !strings.HasPrefix(kv[:idx], prefix) {
+			continue
+		}
+
+		name := kv[len(prefix):idx]
+		if name == "" {
+			continue
+		}
+
+		result = append(result, Override{
+			Pointer: &Pointer{Parts: strings.Split(name, sep)},
+			Value:   kv[idx+1:],
+		})
+	}
+
+	return result
+}
+
+// ApplyOverrides sets the value of each override in v using Set, and returns
+// the result like Set. Every override is applied even if others fail, and
+// the errors of those that failed are returned together as OverrideErrors.
+func ApplyOverrides(v interface{}, overrides []Override) (interface{}, error) {
+	var errs OverrideErrors
+	for _, o := range overrides {
+		result, err := o.Pointer.Set(v, o.Value)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		v = result
+	}
+
+	if len(errs) > 0 {
+		return v, errs
+	}
+
+	return v, nil
+}
+
+// OverrideErrors is the errors of the overrides that ApplyOverrides
+// couldn't apply. errors.Is reports whether any of the errors matches.
+type OverrideErrors []error
+
+func (e OverrideErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%d override(s) failed: %s", len(e), strings.Join(msgs, "; "))
+}
+
+// Is reports whether any of the errors matches target.
+func (e OverrideErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package pointerstructure
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOverride(t *testing.T) {
+	cases := []struct {
+		Input   string
+		Pointer string
+		Value   string
+		Err     bool
+	}{
+		{"/server/port=8080", "/server/port", "8080", false},
+		{"/args=a=b", "/args", "a=b", false},
+		{"/empty=", "/empty", "", false},
+		{"=value", "", "value", false},
+		{"/missing", "", "", true},
+		{"server=1", "", "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			o, err := ParseOverride(tc.Input)
+			if (err != nil) != tc.Err {
+				t.Fatalf("err: %s", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if o.Pointer.String() != tc.Pointer || o.Value != tc.Value {
+				t.Fatalf("bad: %s = %q", o.Pointer, o.Value)
+			}
+		})
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	environ := []string{
+		"HOME=/root",
+		"APP__server__port=8080",
+		"APP__name=a=b",
+		"APP__=ignored",
+		"APPLICATION=ignored",
+	}
+
+	actual := EnvOverrides(environ, "APP__", "__")
+	expected := []Override{
+		{Pointer: &Pointer{Parts: []string{"server", "port"}}, Value: "8080"},
+		{Pointer: &Pointer{Parts: []string{"name"}}, Value: "a=b"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	type server struct {
+		Port    int
+		Debug   bool
+		Timeout float64
+	}
+	type config struct {
+		Name   string
+		Server server
+		Tags   map[string]string
+	}
+
+	cfg := &config{Tags: map[string]string{}}
+
+	var overrides []Override
+	for _, s := range []string{"/Name=web", "/Server/Port=8080", "/Tags/env=prod"} {
+		o, err := ParseOverride(s)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		overrides = append(overrides, o)
+	}
+
+	env := EnvOverrides([]string{"APP_SERVER_DEBUG=true", "APP_SERVER_TIMEOUT=1.5"}, "APP_", "_")
+	for _, o := range env {
+		o.Pointer.Config.CaseInsensitive = true
+	}
+	overrides = append(overrides, env...)
+
+	if _, err := ApplyOverrides(cfg, overrides); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &config{
+		Name:   "web",
+		Server: server{Port: 8080, Debug: true, Timeout: 1.5},
+		Tags:   map[string]string{"env": "prod"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("bad: %#v", cfg)
+	}
+}
+
+func TestApplyOverrides_errors(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": 1,
+		"b": []interface{}{1},
+	}
+
+	overrides := []Override{
+		{Pointer: MustParse("/missing/x"), Value: "1"},
+		{Pointer: MustParse("/a"), Value: "2"},
+		{Pointer: MustParse("/b/5"), Value: "3"},
+	}
+
+	_, err := ApplyOverrides(doc, overrides)
+	var errs OverrideErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("bad: %#v", err)
+	}
+
+	if !errors.Is(err, ErrNotFound) || !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("bad: %s", err)
+	}
+	if !strings.HasPrefix(err.Error(), "2 override(s) failed: ") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// The other overrides are still applied
+	if doc["a"] != "2" {
+		t.Fatalf("bad: %#v", doc)
+	}
+}
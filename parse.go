@@ -2,6 +2,7 @@ package pointerstructure
 
 import (
 	"fmt"
+	"net/url"
 	"strings"
 )
 
@@ -46,6 +47,26 @@ func Parse(input string) (*Pointer, error) {
 	return &Pointer{Parts: parts}, nil
 }
 
+// ParseURIFragment parses a pointer from its URI fragment identifier
+// representation as specified by RFC 6901 section 6, for example
+// "#/components/schemas/Pet" as used by JSON Schema and OpenAPI
+// references. The input must start with '#' and is percent-decoded
+// before being parsed.
+func ParseURIFragment(input string) (*Pointer, error) {
+	if !strings.HasPrefix(input, "#") {
+		return nil, fmt.Errorf(
+			"parse Go pointer fragment %q: %w", input, ErrParse)
+	}
+
+	unescaped, err := url.PathUnescape(input[1:])
+	if err != nil {
+		return nil, fmt.Errorf(
+			"parse Go pointer fragment %q: %w: %s", input, ErrParse, err)
+	}
+
+	return Parse(unescaped)
+}
+
 // MustParse is like Parse but panics if the input cannot be parsed.
 func MustParse(input string) *Pointer {
 	p, err := Parse(input)
@@ -83,3 +83,70 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestParseURIFragment(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Input    string
+		Expected []string
+		Err      bool
+	}{
+		{
+			"root",
+			"#",
+			nil,
+			false,
+		},
+
+		{
+			"basic",
+			"#/components/schemas/Pet",
+			[]string{"components", "schemas", "Pet"},
+			false,
+		},
+
+		{
+			"percent-encoded",
+			"#/a%20b/c%25d/e~1f",
+			[]string{"a b", "c%d", "e/f"},
+			false,
+		},
+
+		{
+			"no hash",
+			"/foo",
+			nil,
+			true,
+		},
+
+		{
+			"relative",
+			"#foo",
+			nil,
+			true,
+		},
+
+		{
+			"bad escape",
+			"#/a%zz",
+			nil,
+			true,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			p, err := ParseURIFragment(tc.Input)
+			if (err != nil) != tc.Err {
+				t.Fatalf("err: %s", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(p.Parts, tc.Expected) {
+				t.Fatalf("bad: %#v", p.Parts)
+			}
+		})
+	}
+}
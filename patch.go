@@ -0,0 +1,442 @@
+package pointerstructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Operation is a single operation of a JSON Patch (RFC 6902).
+type Operation struct {
+	// Op is the operation to perform: "add", "remove", "replace", "move",
+	// "copy" or "test".
+	Op string `json:"op"`
+
+	// Path is the pointer to the value the operation applies to.
+	Path string `json:"path"`
+
+	// From is the pointer to the source value of "move" and "copy".
+	From string `json:"from,omitempty"`
+
+	// Value is the value for "add", "replace" and "test".
+	Value interface{} `json:"value,omitempty"`
+}
+
+// UnmarshalJSON decodes the operation. Like RFC 6902 requires, decoding
+// "add", "replace" and "test" operations without a value member is an
+// error, rather than using a null value.
+func (o *Operation) UnmarshalJSON(data []byte) error {
+	type operation Operation
+	var raw struct {
+		operation
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*o = Operation(raw.operation)
+	if raw.Value == nil {
+		switch o.Op {
+		case "add", "replace", "test":
+			return fmt.Errorf("%s operation at %q has no value", o.Op, o.Path)
+		}
+
+		return nil
+	}
+
+	return json.Unmarshal(raw.Value, &o.Value)
+}
+
+// MarshalJSON encodes the operation, including the value even if it is
+// null for the operations that require one.
+func (o Operation) MarshalJSON() ([]byte, error) {
+	type operation Operation
+	switch o.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			operation
+			Value interface{} `json:"value"`
+		}{operation(o), o.Value})
+
+	default:
+		return json.Marshal(operation(o))
+	}
+}
+
+// Patch is a JSON Patch (RFC 6902), a list of operations that are applied
+// in order.
+type Patch []Operation
+
+// Apply applies the patch to doc and returns the result, like Set.
+//
+// Unlike Set, "add" inserts into a slice at the given index rather than
+// replacing the element there. Since the length of an array is fixed,
+// adding to one returns an error wrapping ErrInvalidKind. "remove" and
+// "replace" return an error if the value doesn't exist. If a "test"
+// operation doesn't match, an error wrapping ErrTestFailed is returned.
+//
+// If an operation fails, doc may have been partially modified. Use
+// ApplyWithConfig with Config.Immutable to leave doc unmodified.
+func (p Patch) Apply(doc interface{}) (interface{}, error) {
+	return p.ApplyWithConfig(doc, Config{})
+}
+
+// ApplyWithConfig is like Apply, but uses the given Config for the
+// pointers of the operations.
+func (p Patch) ApplyWithConfig(doc interface{}, c Config) (interface{}, error) {
+	for i, op := range p {
+		var err error
+		doc, err = op.apply(doc, c)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (o Operation) apply(doc interface{}, c Config) (interface{}, error) {
+	path, err := Parse(o.Path)
+	if err != nil {
+		return nil, err
+	}
+	path.Config = c
+
+	switch o.Op {
+	case "add":
+		return path.add(doc, o.Value)
+
+	case "remove":
+		if _, err := path.Get(doc); err != nil {
+			return nil, err
+		}
+
+		return path.Delete(doc)
+
+	case "replace":
+		if _, err := path.Get(doc); err != nil {
+			return nil, err
+		}
+
+		return path.Set(doc, o.Value)
+
+	case "move", "copy":
+		from, err := o.from()
+		if err != nil {
+			return nil, err
+		}
+		from.Config = c
+
+		value, err := from.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Op == "move" {
+			doc, err = from.Delete(doc)
+		} else {
+			value, err = CloneWithConfig(value, c)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return path.add(doc, value)
+
+	case "test":
+		actual, err := path.Get(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if !patchValuesEqual(actual, o.Value) {
+			return nil, fmt.Errorf("%w: %#v != %#v", ErrTestFailed, actual, o.Value)
+		}
+
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", o.Op)
+	}
+}
+
+// from parses the From pointer, which for "move" can't be a parent of Path.
+func (o Operation) from() (*Pointer, error) {
+	from, err := Parse(o.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Op == "move" && strings.HasPrefix(o.Path, o.From+"/") {
+		return nil, fmt.Errorf("cannot move %s into itself", o.From)
+	}
+
+	return from, nil
+}
+
+// add implements the "add" operation, which is Set except that adding to
+// a slice inserts the value rather than replacing an element.
+func (p *Pointer) add(doc, value interface{}) (interface{}, error) {
+	if p.IsRoot() {
+		return value, nil
+	}
+
+	part := p.Parts[len(p.Parts)-1]
+	if part == "-" {
+		return p.Set(doc, value)
+	}
+
+	parentP := p.Parent()
+	parent, err := parentP.Get(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s := reflect.ValueOf(parent)
+	for s.Kind() == reflect.Ptr || s.Kind() == reflect.Interface {
+		s = s.Elem()
+	}
+	switch s.Kind() {
+	case reflect.Slice:
+	case reflect.Array:
+		return nil, fmt.Errorf(
+			"add %s: %w: cannot insert into array %s", p, ErrInvalidKind, s.Type())
+	default:
+		return p.Set(doc, value)
+	}
+
+	// Inserting at the length appends
+	idx, err := p.sliceIndex(part, s.Len()+1)
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", p, err)
+	}
+
+	elem, err := p.Config.coerceValue(reflect.ValueOf(value), s.Type().Elem())
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", p, err)
+	}
+
+	result := reflect.MakeSlice(s.Type(), s.Len()+1, s.Len()+1)
+	reflect.Copy(result, s.Slice(0, idx))
+	result.Index(idx).Set(elem)
+	reflect.Copy(result.Slice(idx+1, result.Len()), s.Slice(idx, s.Len()))
+
+	return parentP.Set(doc, result.Interface())
+}
+
+// patchValuesEqual returns true if the actual value matches the expected
+// value of a "test" operation. Numbers of different types, such as a
+// float64 decoded from JSON and an int field, are compared by value, also
+// within maps, slices and arrays.
+func patchValuesEqual(actual, expected interface{}) bool {
+	if reflect.DeepEqual(actual, expected) {
+		return true
+	}
+
+	a, b := patchValue(actual), patchValue(expected)
+	if !a.IsValid() || !b.IsValid() {
+		return false
+	}
+
+	if isNumber(a.Kind()) && isNumber(b.Kind()) {
+		floatType := reflect.TypeOf(float64(0))
+		return a.Convert(floatType).Float() == b.Convert(floatType).Float()
+	}
+
+	switch {
+	case a.Kind() == reflect.Map && b.Kind() == reflect.Map:
+		ak, bk := a.Type().Key(), b.Type().Key()
+		sameKey := ak == bk || (ak.Kind() == reflect.String && bk.Kind() == reflect.String)
+		if a.Len() != b.Len() || !sameKey {
+			return false
+		}
+
+		iter := a.MapRange()
+		for iter.Next() {
+			bv := b.MapIndex(iter.Key().Convert(bk))
+			if !bv.IsValid() || !patchValuesEqual(iter.Value().Interface(), bv.Interface()) {
+				return false
+			}
+		}
+
+		return true
+
+	case isList(a.Kind()) && isList(b.Kind()):
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if !patchValuesEqual(a.Index(i).Interface(), b.Index(i).Interface()) {
+				return false
+			}
+		}
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+// patchValue returns the value to compare for v, following pointers and
+// decoding a json.Number into a float64.
+func patchValue(v interface{}) reflect.Value {
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return reflect.ValueOf(f)
+		}
+	}
+
+	result := reflect.ValueOf(v)
+	for result.Kind() == reflect.Ptr || result.Kind() == reflect.Interface {
+		result = result.Elem()
+	}
+
+	return result
+}
+
+func isList(k reflect.Kind) bool {
+	return k == reflect.Slice || k == reflect.Array
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+// ApplyJSON applies the patch to the encoded JSON document doc and returns
+// the modified document. Like SetJSON, only the bytes of the values being
+// modified are changed, so the rest of the document is preserved exactly.
+func (p Patch) ApplyJSON(doc []byte) ([]byte, error) {
+	for i, op := range p {
+		var err error
+		doc, err = op.applyJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (o Operation) applyJSON(doc []byte) ([]byte, error) {
+	path, err := Parse(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add":
+		return path.addJSON(doc, o.Value)
+
+	case "remove":
+		if _, err := path.findJSON(doc); err != nil {
+			return nil, err
+		}
+
+		return DeleteJSON(doc, path)
+
+	case "replace":
+		if _, err := path.findJSON(doc); err != nil {
+			return nil, err
+		}
+
+		return SetJSON(doc, path, o.Value)
+
+	case "move", "copy":
+		from, err := o.from()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := GetJSON(bytes.NewReader(doc), from)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Op == "move" {
+			doc, err = DeleteJSON(doc, from)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return path.addJSON(doc, value)
+
+	case "test":
+		raw, err := GetJSON(bytes.NewReader(doc), path)
+		if err != nil {
+			return nil, err
+		}
+
+		// Compare the decoded values so that formatting doesn't matter
+		var actual interface{}
+		if err := json.Unmarshal(raw, &actual); err != nil {
+			return nil, err
+		}
+
+		expected, err := decodeJSONValue(o.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			return nil, fmt.Errorf("%w: %s != %#v", ErrTestFailed, raw, o.Value)
+		}
+
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("unknown operation %q", o.Op)
+	}
+}
+
+// addJSON is add for an encoded JSON document.
+func (p *Pointer) addJSON(doc []byte, value interface{}) ([]byte, error) {
+	if p.IsRoot() {
+		return SetJSON(doc, p, value)
+	}
+
+	c, err := p.parentJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	part := p.Parts[len(p.Parts)-1]
+	if c.object || part == "-" {
+		return SetJSON(doc, p, value)
+	}
+
+	// Inserting at the length appends
+	idx, err := p.sliceIndex(part, len(c.members)+1)
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", p, err)
+	}
+
+	encoded, err := encodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("add %s: %w", p, err)
+	}
+
+	if idx == len(c.members) {
+		return c.insert(doc, encoded), nil
+	}
+
+	start := c.members[idx].start()
+	return spliceJSON(doc, jsonSpan{start, start}, append(encoded, ',')), nil
+}
+
+// decodeJSONValue returns v as it would be decoded from JSON.
+func decodeJSONValue(v interface{}) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	err = json.Unmarshal(encoded, &result)
+	return result, err
+}
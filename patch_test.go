@@ -0,0 +1,328 @@
+package pointerstructure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestPatchApply(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Doc    string
+		Patch  string
+		Output string
+		Err    error
+	}{
+		{
+			"add key",
+			`{"a":1}`,
+			`[{"op":"add","path":"/b","value":2}]`,
+			`{"a":1,"b":2}`,
+			nil,
+		},
+		{
+			"add inserts into array",
+			`{"a":[1,3]}`,
+			`[{"op":"add","path":"/a/1","value":2}]`,
+			`{"a":[1,2,3]}`,
+			nil,
+		},
+		{
+			"add at length appends",
+			`{"a":[1]}`,
+			`[{"op":"add","path":"/a/1","value":2},{"op":"add","path":"/a/-","value":3}]`,
+			`{"a":[1,2,3]}`,
+			nil,
+		},
+		{
+			"add past length",
+			`{"a":[1]}`,
+			`[{"op":"add","path":"/a/2","value":2}]`,
+			``,
+			ErrOutOfRange,
+		},
+		{
+			"add null",
+			`{"a":1}`,
+			`[{"op":"add","path":"/a","value":null}]`,
+			`{"a":null}`,
+			nil,
+		},
+		{
+			"remove",
+			`{"a":[1,2],"b":1}`,
+			`[{"op":"remove","path":"/a/0"},{"op":"remove","path":"/b"}]`,
+			`{"a":[2]}`,
+			nil,
+		},
+		{
+			"remove missing",
+			`{"a":1}`,
+			`[{"op":"remove","path":"/b"}]`,
+			``,
+			ErrNotFound,
+		},
+		{
+			"replace",
+			`{"a":1}`,
+			`[{"op":"replace","path":"/a","value":{"b":2}}]`,
+			`{"a":{"b":2}}`,
+			nil,
+		},
+		{
+			"replace missing",
+			`{"a":1}`,
+			`[{"op":"replace","path":"/b","value":2}]`,
+			``,
+			ErrNotFound,
+		},
+		{
+			"move",
+			`{"a":{"b":1},"c":[]}`,
+			`[{"op":"move","from":"/a/b","path":"/c/0"}]`,
+			`{"a":{},"c":[1]}`,
+			nil,
+		},
+		{
+			"move into itself",
+			`{"a":{"b":1}}`,
+			`[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			``,
+			nil,
+		},
+		{
+			"copy",
+			`{"a":{"b":1}}`,
+			`[{"op":"copy","from":"/a","path":"/c"},{"op":"add","path":"/c/d","value":2}]`,
+			`{"a":{"b":1},"c":{"b":1,"d":2}}`,
+			nil,
+		},
+		{
+			"test",
+			`{"a":[1,"x"]}`,
+			`[{"op":"test","path":"/a","value":[1,"x"]}]`,
+			`{"a":[1,"x"]}`,
+			nil,
+		},
+		{
+			"test failed",
+			`{"a":1}`,
+			`[{"op":"test","path":"/a","value":2}]`,
+			``,
+			ErrTestFailed,
+		},
+		{
+			"bad path",
+			`{"a":1}`,
+			`[{"op":"add","path":"a","value":2}]`,
+			``,
+			ErrParse,
+		},
+		{
+			"unknown operation",
+			`{"a":1}`,
+			`[{"op":"frobnicate","path":"/a"}]`,
+			``,
+			nil,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			var patch Patch
+			if err := json.Unmarshal([]byte(tc.Patch), &patch); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			var doc interface{}
+			if err := json.Unmarshal([]byte(tc.Doc), &doc); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			actual, err := patch.Apply(doc)
+			actualJSON, errJSON := patch.ApplyJSON([]byte(tc.Doc))
+			if tc.Output == "" {
+				if err == nil || errJSON == nil {
+					t.Fatalf("expected errors, got: %v, %v", err, errJSON)
+				}
+				if tc.Err != nil && (!errors.Is(err, tc.Err) || !errors.Is(errJSON, tc.Err)) {
+					t.Fatalf("expected %v, got: %v, %v", tc.Err, err, errJSON)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if errJSON != nil {
+				t.Fatalf("err: %s", errJSON)
+			}
+
+			var expected interface{}
+			if err := json.Unmarshal([]byte(tc.Output), &expected); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("bad: %#v != %#v", actual, expected)
+			}
+			if string(actualJSON) != tc.Output {
+				t.Fatalf("bad JSON: %s != %s", actualJSON, tc.Output)
+			}
+		})
+	}
+}
+
+func TestPatchApply_struct(t *testing.T) {
+	type server struct {
+		Port  int
+		Hosts []string
+	}
+
+	doc := &server{Port: 80, Hosts: []string{"a", "c"}}
+	patch := Patch{
+		{Op: "test", Path: "/Port", Value: 80.0},
+		{Op: "replace", Path: "/Port", Value: 443},
+		{Op: "add", Path: "/Hosts/1", Value: "b"},
+	}
+
+	if _, err := patch.Apply(doc); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &server{Port: 443, Hosts: []string{"a", "b", "c"}}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("bad: %#v", doc)
+	}
+}
+
+func TestPatchApplyWithConfig_immutable(t *testing.T) {
+	doc := map[string]interface{}{"a": []interface{}{1}}
+	patch := Patch{
+		{Op: "add", Path: "/a/0", Value: 0},
+		{Op: "add", Path: "/b", Value: 2},
+	}
+
+	actual, err := patch.ApplyWithConfig(doc, Config{Immutable: true})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{"a": []interface{}{0, 1}, "b": 2}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if !reflect.DeepEqual(doc, map[string]interface{}{"a": []interface{}{1}}) {
+		t.Fatalf("input modified: %#v", doc)
+	}
+}
+
+func TestPatchApplyJSON_preservesFormatting(t *testing.T) {
+	doc := []byte(`{
+  "z": 1,
+  "a": [1, 3]
+}`)
+	patch := Patch{
+		{Op: "add", Path: "/a/1", Value: 2},
+		{Op: "move", From: "/z", Path: "/y"},
+	}
+
+	actual, err := patch.ApplyJSON(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := `{
+  "a": [1, 2,3],"y":1
+}`
+	if string(actual) != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestPatchApply_nestedNumbers(t *testing.T) {
+	type item struct {
+		Counts map[string]int
+		Sizes  [2]uint8
+	}
+
+	doc := &item{Counts: map[string]int{"a": 1}, Sizes: [2]uint8{2, 3}}
+	patch := Patch{
+		{Op: "test", Path: "/Counts", Value: map[string]interface{}{"a": 1.0}},
+		{Op: "test", Path: "/Sizes", Value: []interface{}{2.0, json.Number("3")}},
+	}
+	if _, err := patch.Apply(doc); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	patch = Patch{
+		{Op: "test", Path: "/Sizes", Value: []interface{}{2.0, 4.0}},
+	}
+	if _, err := patch.Apply(doc); !errors.Is(err, ErrTestFailed) {
+		t.Fatalf("expected ErrTestFailed, got: %v", err)
+	}
+}
+
+func TestPatchApply_array(t *testing.T) {
+	doc := &struct{ A [2]int }{A: [2]int{1, 2}}
+
+	// Inserting into a fixed-size array isn't possible
+	_, err := Patch{{Op: "add", Path: "/A/0", Value: 0}}.Apply(doc)
+	if !errors.Is(err, ErrInvalidKind) {
+		t.Fatalf("expected ErrInvalidKind, got: %v", err)
+	}
+	if doc.A != [2]int{1, 2} {
+		t.Fatalf("bad: %#v", doc.A)
+	}
+}
+
+func TestOperation_UnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		Input string
+		Err   bool
+	}{
+		{`{"op":"add","path":"/a","value":null}`, false},
+		{`{"op":"add","path":"/a"}`, true},
+		{`{"op":"replace","path":"/a"}`, true},
+		{`{"op":"test","path":"/a"}`, true},
+		{`{"op":"remove","path":"/a"}`, false},
+		{`{"op":"copy","from":"/a","path":"/b"}`, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Input, func(t *testing.T) {
+			var op Operation
+			err := json.Unmarshal([]byte(tc.Input), &op)
+			if (err != nil) != tc.Err {
+				t.Fatalf("err: %v", err)
+			}
+		})
+	}
+
+	var op Operation
+	if err := json.Unmarshal([]byte(`{"op":"copy","from":"/a","path":"/b"}`), &op); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if op != (Operation{Op: "copy", From: "/a", Path: "/b"}) {
+		t.Fatalf("bad: %#v", op)
+	}
+}
+
+func TestOperation_MarshalJSON(t *testing.T) {
+	patch := Patch{
+		{Op: "add", Path: "/a", Value: nil},
+		{Op: "remove", Path: "/b"},
+		{Op: "move", From: "/c", Path: "/d"},
+	}
+
+	actual, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","path":"/d","from":"/c"}]`
+	if string(actual) != expected {
+		t.Fatalf("bad: %s", actual)
+	}
+}
@@ -5,11 +5,8 @@
 package pointerstructure
 
 import (
-	"fmt"
 	"reflect"
 	"strings"
-
-	"github.com/mitchellh/mapstructure"
 )
 
 // ValueTransformationHookFn transforms a Go data structure into another.
@@ -18,6 +15,11 @@ import (
 // working with protocol buffers' well-known types.
 type ValueTransformationHookFn func(reflect.Value) reflect.Value
 
+// ValueReverseTransformationHookFn reverses a ValueTransformationHookFn.
+// It is given the original value and the transformed value, which may have
+// been modified, and returns the value to store in place of the original.
+type ValueReverseTransformationHookFn func(original, transformed reflect.Value) reflect.Value
+
 type Config struct {
 	// The tag name that pointerstructure reads for field names. This
 	// defaults to "pointer"
@@ -26,7 +28,98 @@ type Config struct {
 	// provided JSON Pointer when Get is used.  The returned value from this
 	// hook is then used for matching for all following parts of the JSON
 	// Pointer.  If this returns a nil interface Get will return an error.
+	//
+	// Set and Delete also use this hook to find the parent of the value
+	// being modified.
 	ValueTransformationHook ValueTransformationHookFn
+
+	// ValueReverseTransformationHook is the inverse of
+	// ValueTransformationHook and is used by Set and Delete. After the
+	// transformed parent of the pointer is modified, this hook is called
+	// with the original and the modified parent, and the result is written
+	// back in place of the original. This repeats for each parent up to the
+	// root so that the change reaches the underlying structure. If this
+	// returns a nil interface Set and Delete will return an error.
+	ValueReverseTransformationHook ValueReverseTransformationHookFn
+
+	// CaseInsensitive makes struct field names and the keys of maps with
+	// string keys match pointer parts regardless of case. An exact match is
+	// always preferred. If there is no exact match and multiple names match,
+	// an error wrapping ErrAmbiguous is returned.
+	CaseInsensitive bool
+
+	// KeyNormalizer, if set, is used like CaseInsensitive but compares
+	// names and parts after passing both through this function. This takes
+	// precedence over CaseInsensitive.
+	KeyNormalizer func(string) string
+
+	// Coercer converts pointer parts to map keys and slice indexes, and
+	// values given to Set to the type they are written to. This defaults
+	// to WeakCoercer.
+	Coercer Coercer
+
+	// Immutable makes Set and Delete leave their input unmodified. Rather
+	// than modifying containers in place, the maps, slices, arrays, structs
+	// and pointers along the path of the pointer are shallow copied and a
+	// new root is returned. Everything not on the path is shared with the
+	// input. Values implementing PointerSetter or PointerDeleter are still
+	// modified in place. Structs embedded by pointer are copied too when
+	// setting their promoted fields.
+	Immutable bool
+
+	// CloneHook is called for each value copied by CloneWithConfig to allow
+	// customizing how it is copied.
+	CloneHook CloneHookFn
+
+	// DecodeRawJSON makes pointers resolve within json.RawMessage values,
+	// rather than treating them as a byte slice. The json.RawMessage is
+	// decoded when a pointer goes through it, and Set and Delete encode
+	// the modified value back into the json.RawMessage. Numbers are decoded
+	// as json.Number, so they are encoded back unchanged.
+	DecodeRawJSON bool
+
+	// FollowRefs makes pointers resolve through JSON Schema and OpenAPI
+	// style references, which are maps with a "$ref" key whose value is
+	// a URI fragment pointer such as "#/components/schemas/Pet". When a
+	// pointer reaches a reference, it continues from the value that the
+	// reference refers to within the root document. Only local references
+	// are supported.
+	FollowRefs bool
+
+	// ArrayDeleteShift makes Delete of an array element shift the following
+	// elements to the left and set the last element to its zero value,
+	// since the length of an array can't change. By default deleting an
+	// array element returns an error wrapping ErrInvalidKind.
+	ArrayDeleteShift bool
+
+	// NegativeIndex makes negative slice and array indexes count from the
+	// end, so "-1" is the last element and "-2" the one before it. This is
+	// not allowed by RFC 6901 and so is disabled by default.
+	NegativeIndex bool
+}
+
+func (c *Config) tagName() string {
+	if c.TagName == "" {
+		return "pointer"
+	}
+
+	return c.TagName
+}
+
+// keyMatcher returns the function used to match a name against a part when
+// they are not exactly equal, or nil if only exact matches are allowed.
+func (c *Config) keyMatcher() func(name, part string) bool {
+	if c.KeyNormalizer != nil {
+		return func(name, part string) bool {
+			return c.KeyNormalizer(name) == c.KeyNormalizer(part)
+		}
+	}
+
+	if c.CaseInsensitive {
+		return strings.EqualFold
+	}
+
+	return nil
 }
 
 // Pointer represents a pointer to a specific value. You can construct
@@ -116,30 +209,3 @@ func (p *Pointer) Parent() *Pointer {
 func (p *Pointer) IsRoot() bool {
 	return len(p.Parts) == 0
 }
-
-// coerce is a helper to coerce a value to a specific type if it must
-// and if its possible. If it isn't possible, an error is returned.
-func coerce(value reflect.Value, to reflect.Type) (reflect.Value, error) {
-	// If the value is already assignable to the type, then let it go
-	if value.Type().AssignableTo(to) {
-		return value, nil
-	}
-
-	// If a direct conversion is possible, do that
-	if value.Type().ConvertibleTo(to) {
-		return value.Convert(to), nil
-	}
-
-	// Create a new value to hold our result
-	result := reflect.New(to)
-
-	// Decode
-	if err := mapstructure.WeakDecode(value.Interface(), result.Interface()); err != nil {
-		return result, fmt.Errorf(
-			"%w %#v to type %s", ErrConvert,
-			value.Interface(), to.String())
-	}
-
-	// We need to indirect the value since reflect.New always creates a pointer
-	return reflect.Indirect(result), nil
-}
@@ -0,0 +1,222 @@
+package pointerstructure
+
+import (
+	"sort"
+)
+
+// PointerSet is a set of pointers. It is stored as a trie keyed on the
+// parts of the pointers, so it can efficiently answer queries about
+// pointers sharing a prefix, such as whether any pointer in the set is
+// below "/spec/containers".
+//
+// Pointers are compared by their parts only, the Config is ignored.
+// The zero value is an empty set ready to use.
+type PointerSet struct {
+	root pointerNode
+	len  int
+}
+
+// pointerNode is a node of the trie for a PointerSet. Nodes without
+// children that aren't in the set are removed, so the existence of a node
+// means there is a pointer in the set at or below it.
+type pointerNode struct {
+	children map[string]*pointerNode
+	present  bool
+}
+
+// NewPointerSet returns a set containing the given pointers.
+func NewPointerSet(ps ...*Pointer) *PointerSet {
+	var s PointerSet
+	for _, p := range ps {
+		s.Add(p)
+	}
+
+	return &s
+}
+
+// Len returns the number of pointers in the set.
+func (s *PointerSet) Len() int { return s.len }
+
+// Add adds the pointer to the set. It returns true if the pointer wasn't
+// already in the set.
+func (s *PointerSet) Add(p *Pointer) bool {
+	n := &s.root
+	for _, part := range p.Parts {
+		child, ok := n.children[part]
+		if !ok {
+			if n.children == nil {
+				n.children = map[string]*pointerNode{}
+			}
+
+			child = &pointerNode{}
+			n.children[part] = child
+		}
+
+		n = child
+	}
+
+	if n.present {
+		return false
+	}
+
+	n.present = true
+	s.len++
+	return true
+}
+
+// Remove removes the pointer from the set. It returns true if the pointer
+// was in the set. Pointers below p are not removed.
+func (s *PointerSet) Remove(p *Pointer) bool {
+	// Keep the path so that nodes left empty can be pruned
+	path := make([]*pointerNode, 0, len(p.Parts)+1)
+	n := &s.root
+	path = append(path, n)
+	for _, part := range p.Parts {
+		n = n.children[part]
+		if n == nil {
+			return false
+		}
+
+		path = append(path, n)
+	}
+
+	if !n.present {
+		return false
+	}
+
+	n.present = false
+	s.len--
+
+	// Prune empty nodes from the bottom up
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].present || len(path[i].children) > 0 {
+			break
+		}
+
+		delete(path[i-1].children, p.Parts[i-1])
+	}
+
+	return true
+}
+
+// Contains returns true if the pointer is in the set.
+func (s *PointerSet) Contains(p *Pointer) bool {
+	n := s.node(p)
+	return n != nil && n.present
+}
+
+// HasDescendant returns true if the set contains any pointer below p,
+// not including p itself. For example, if the set contains "/a/b" then
+// HasDescendant is true for "/a" and "" but not for "/a/b".
+func (s *PointerSet) HasDescendant(p *Pointer) bool {
+	n := s.node(p)
+	return n != nil && len(n.children) > 0
+}
+
+// Ancestors returns the pointers in the set that are above p, not
+// including p itself. The result is ordered from the root down.
+func (s *PointerSet) Ancestors(p *Pointer) []*Pointer {
+	var result []*Pointer
+	n := &s.root
+	for i, part := range p.Parts {
+		if n.present {
+			result = append(result, pointerFromParts(p.Parts[:i]))
+		}
+
+		n = n.children[part]
+		if n == nil {
+			break
+		}
+	}
+
+	return result
+}
+
+// Minimize removes every pointer that has an ancestor in the set, leaving
+// the smallest set of pointers that covers the same values.
+func (s *PointerSet) Minimize() {
+	s.len = s.root.minimize()
+}
+
+// minimize removes the descendants of present nodes and returns the number
+// of pointers remaining in this subtree.
+func (n *pointerNode) minimize() int {
+	if n.present {
+		n.children = nil
+		return 1
+	}
+
+	count := 0
+	for _, child := range n.children {
+		count += child.minimize()
+	}
+
+	return count
+}
+
+// Pointers returns the pointers in the set, in the order that Sort
+// would sort them.
+func (s *PointerSet) Pointers() []*Pointer {
+	result := make([]*Pointer, 0, s.len)
+	s.Walk(func(p *Pointer) bool {
+		result = append(result, p)
+		return true
+	})
+
+	return result
+}
+
+// Walk calls fn for each pointer in the set, in the order that Sort would
+// sort them. If fn returns false, the walk stops. The set must not be
+// modified during the walk.
+func (s *PointerSet) Walk(fn func(*Pointer) bool) {
+	s.root.walk(nil, fn)
+}
+
+func (n *pointerNode) walk(parts []string, fn func(*Pointer) bool) bool {
+	if n.present && !fn(pointerFromParts(parts)) {
+		return false
+	}
+
+	for _, part := range n.sortedParts() {
+		if !n.children[part].walk(append(parts, part), fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortedParts returns the parts of the children in the order used by Sort.
+func (n *pointerNode) sortedParts() []string {
+	parts := make([]string, 0, len(n.children))
+	for part := range n.children {
+		parts = append(parts, part)
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return compareParts(parts[i], parts[j]) < 0
+	})
+
+	return parts
+}
+
+// node returns the node for the pointer p, or nil if there is none.
+func (s *PointerSet) node(p *Pointer) *pointerNode {
+	n := &s.root
+	for _, part := range p.Parts {
+		n = n.children[part]
+		if n == nil {
+			return nil
+		}
+	}
+
+	return n
+}
+
+// pointerFromParts returns a pointer with a copy of parts.
+func pointerFromParts(parts []string) *Pointer {
+	result := make([]string, len(parts))
+	copy(result, parts)
+	return &Pointer{Parts: result}
+}
@@ -0,0 +1,129 @@
+package pointerstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func pointerStrings(ps []*Pointer) []string {
+	result := make([]string, len(ps))
+	for i, p := range ps {
+		result[i] = p.String()
+	}
+
+	return result
+}
+
+func TestPointerSet_AddRemove(t *testing.T) {
+	var s PointerSet
+	if !s.Add(MustParse("/spec/containers/0/image")) {
+		t.Fatal("expected add")
+	}
+	if s.Add(MustParse("/spec/containers/0/image")) {
+		t.Fatal("expected duplicate")
+	}
+	s.Add(MustParse("/spec/replicas"))
+	s.Add(MustParse("/metadata"))
+
+	if s.Len() != 3 {
+		t.Fatalf("bad len: %d", s.Len())
+	}
+
+	if !s.Contains(MustParse("/spec/replicas")) {
+		t.Fatal("expected contains")
+	}
+	if s.Contains(MustParse("/spec")) {
+		t.Fatal("expected not contains")
+	}
+
+	if !s.HasDescendant(MustParse("/spec/containers")) {
+		t.Fatal("expected descendant")
+	}
+	if s.HasDescendant(MustParse("/spec/replicas")) {
+		t.Fatal("expected no descendant")
+	}
+	if s.HasDescendant(MustParse("/status")) {
+		t.Fatal("expected no descendant")
+	}
+
+	if !s.Remove(MustParse("/spec/containers/0/image")) {
+		t.Fatal("expected remove")
+	}
+	if s.Remove(MustParse("/spec/containers/0/image")) {
+		t.Fatal("expected already removed")
+	}
+	if s.HasDescendant(MustParse("/spec/containers")) {
+		t.Fatal("expected empty nodes to be pruned")
+	}
+	if s.Len() != 2 {
+		t.Fatalf("bad len: %d", s.Len())
+	}
+}
+
+func TestPointerSet_Ancestors(t *testing.T) {
+	s := NewPointerSet(
+		MustParse(""),
+		MustParse("/a"),
+		MustParse("/a/b/c"),
+		MustParse("/a/b/c/d"),
+		MustParse("/x"),
+	)
+
+	actual := pointerStrings(s.Ancestors(MustParse("/a/b/c/d")))
+	expected := []string{"", "/a", "/a/b/c"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if actual := s.Ancestors(MustParse("")); len(actual) != 0 {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestPointerSet_Minimize(t *testing.T) {
+	s := NewPointerSet(
+		MustParse("/a"),
+		MustParse("/a/b"),
+		MustParse("/c/d"),
+		MustParse("/c/e/f"),
+		MustParse("/c/e"),
+	)
+	s.Minimize()
+
+	actual := pointerStrings(s.Pointers())
+	expected := []string{"/a", "/c/d", "/c/e"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if s.Len() != 3 {
+		t.Fatalf("bad len: %d", s.Len())
+	}
+}
+
+func TestPointerSet_Pointers(t *testing.T) {
+	input := []string{"/items/10", "/items/2", "", "/items/1/a", "/items", "/b"}
+
+	var ps []*Pointer
+	for _, raw := range input {
+		ps = append(ps, MustParse(raw))
+	}
+
+	s := NewPointerSet(ps...)
+	Sort(ps)
+
+	actual := pointerStrings(s.Pointers())
+	expected := pointerStrings(ps)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v != %#v", actual, expected)
+	}
+
+	// Walk stops early
+	count := 0
+	s.Walk(func(*Pointer) bool {
+		count++
+		return count < 2
+	})
+	if count != 2 {
+		t.Fatalf("bad count: %d", count)
+	}
+}
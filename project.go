@@ -0,0 +1,190 @@
+package pointerstructure
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// ProjectOptions configures how ProjectWithOptions builds a projection.
+type ProjectOptions struct {
+	// Config is the configuration used to resolve the pointers.
+	Config Config
+
+	// SparseSlices keeps the selected elements of slices and arrays at
+	// their index, with nil for the elements that weren't selected. By
+	// default only the selected elements are kept, in index order.
+	SparseSlices bool
+
+	// Strict makes pointers that don't exist an error. By default they
+	// are skipped.
+	Strict bool
+}
+
+// Project returns a new document containing only the values at the pointers
+// ptrs in v, at the same location they are in v. Maps and structs along the
+// pointers become map[string]interface{} values keyed by the pointer parts,
+// and slices and arrays become []interface{} values containing only the
+// selected elements. The selected values themselves are deep copies.
+//
+// Pointers that don't exist in v are skipped. If no pointer exists, the
+// result is nil.
+func Project(v interface{}, ptrs []*Pointer) (interface{}, error) {
+	return ProjectWithOptions(v, ptrs, ProjectOptions{})
+}
+
+// ProjectWithOptions is like Project with the given options.
+func ProjectWithOptions(v interface{}, ptrs []*Pointer, opts ProjectOptions) (interface{}, error) {
+	set := NewPointerSet(ptrs...)
+	set.Minimize()
+
+	pr := &projector{opts: opts, root: v}
+	result, ok, err := pr.project(&set.root, nil, v)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+type projector struct {
+	opts ProjectOptions
+	root interface{}
+}
+
+// projectedElem is a selected element of a slice.
+type projectedElem struct {
+	idx   int
+	value interface{}
+}
+
+// project returns the projection of the value v at parts for the pointers
+// below the node n. ok is false if none of the pointers exist.
+func (pr *projector) project(n *pointerNode, parts []string, v interface{}) (result interface{}, ok bool, err error) {
+	if n.present {
+		result, err := CloneWithConfig(v, pr.opts.Config)
+		return result, err == nil, err
+	}
+
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		return pr.projectSlice(n, parts, v, value.Len())
+	}
+
+	object := map[string]interface{}{}
+	for _, part := range n.sortedParts() {
+		projected, ok, err := pr.child(n.children[part], parts, part, v)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			object[part] = projected
+		}
+	}
+
+	return object, len(object) > 0, nil
+}
+
+// projectSlice is project for a slice or array v of the given length.
+// Parts that refer to the same element, such as "1" and "01", or "-1"
+// with Config.NegativeIndex, are projected together.
+func (pr *projector) projectSlice(n *pointerNode, parts []string, v interface{}, length int) (result interface{}, ok bool, err error) {
+	p := &Pointer{Config: pr.opts.Config}
+	byIndex := map[int]*pointerNode{}
+	for _, part := range n.sortedParts() {
+		idx, err := p.sliceIndex(part, length)
+		if err != nil {
+			if _, _, err := pr.child(n.children[part], parts, part, v); err != nil {
+				return nil, false, err
+			}
+
+			continue
+		}
+
+		byIndex[idx] = mergeNodes(byIndex[idx], n.children[part])
+	}
+
+	var elems []projectedElem
+	for idx, child := range byIndex {
+		projected, ok, err := pr.child(child, parts, strconv.Itoa(idx), v)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			elems = append(elems, projectedElem{idx: idx, value: projected})
+		}
+	}
+
+	return pr.slice(elems), len(elems) > 0, nil
+}
+
+// child returns the projection of the child of v with the given part for
+// the node n. Since v is at parts, the child is resolved from v rather
+// than the root.
+func (pr *projector) child(n *pointerNode, parts []string, part string, v interface{}) (result interface{}, ok bool, err error) {
+	child := pointerFromParts(append(parts, part))
+	child.Config = pr.opts.Config
+
+	value, _, err := child.getWithin(pr.root, reflect.ValueOf(v), len(parts), nil)
+	if err != nil {
+		if pr.opts.Strict {
+			return nil, false, err
+		}
+
+		return nil, false, nil
+	}
+
+	return pr.project(n, child.Parts, value.Interface())
+}
+
+// mergeNodes returns a node for the union of the pointers below a and b,
+// either of which may be nil.
+func mergeNodes(a, b *pointerNode) *pointerNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	result := &pointerNode{
+		children: map[string]*pointerNode{},
+		present:  a.present || b.present,
+	}
+	for part, child := range a.children {
+		result.children[part] = child
+	}
+	for part, child := range b.children {
+		result.children[part] = mergeNodes(result.children[part], child)
+	}
+
+	return result
+}
+
+// slice returns the slice containing the selected elements.
+func (pr *projector) slice(elems []projectedElem) []interface{} {
+	sort.SliceStable(elems, func(i, j int) bool {
+		return elems[i].idx < elems[j].idx
+	})
+
+	if !pr.opts.SparseSlices {
+		result := make([]interface{}, len(elems))
+		for i, e := range elems {
+			result[i] = e.value
+		}
+		return result
+	}
+
+	if len(elems) == 0 {
+		return []interface{}{}
+	}
+
+	result := make([]interface{}, elems[len(elems)-1].idx+1)
+	for _, e := range elems {
+		result[e.idx] = e.value
+	}
+	return result
+}
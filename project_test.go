@@ -0,0 +1,161 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	type container struct {
+		Name  string `pointer:"name"`
+		Image string `pointer:"image"`
+	}
+
+	doc := map[string]interface{}{
+		"name": "web",
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"containers": []container{
+				{"app", "app:1"},
+				{"proxy", "proxy:2"},
+				{"log", "log:3"},
+			},
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		Pointers []string
+		Opts     ProjectOptions
+		Output   interface{}
+		Err      error
+	}{
+		{
+			"fields",
+			[]string{"/name", "/spec/replicas"},
+			ProjectOptions{},
+			map[string]interface{}{
+				"name": "web",
+				"spec": map[string]interface{}{"replicas": 3},
+			},
+			nil,
+		},
+		{
+			"covering pointer",
+			[]string{"/spec", "/spec/replicas"},
+			ProjectOptions{},
+			map[string]interface{}{"spec": doc["spec"]},
+			nil,
+		},
+		{
+			"compact slice",
+			[]string{"/spec/containers/2/name", "/spec/containers/0/image"},
+			ProjectOptions{},
+			map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "app:1"},
+						map[string]interface{}{"name": "log"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"sparse slice",
+			[]string{"/spec/containers/2/name", "/spec/containers/0/image"},
+			ProjectOptions{SparseSlices: true},
+			map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"image": "app:1"},
+						nil,
+						map[string]interface{}{"name": "log"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"same element",
+			[]string{"/spec/containers/-1/name", "/spec/containers/2/image", "/spec/containers/00/name"},
+			ProjectOptions{Config: Config{NegativeIndex: true}},
+			map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app"},
+						map[string]interface{}{"name": "log", "image": "log:3"},
+					},
+				},
+			},
+			nil,
+		},
+		{
+			"missing skipped",
+			[]string{"/name", "/spec/missing", "/spec/containers/5", "/other/x"},
+			ProjectOptions{},
+			map[string]interface{}{"name": "web"},
+			nil,
+		},
+		{
+			"nothing found",
+			[]string{"/missing"},
+			ProjectOptions{},
+			nil,
+			nil,
+		},
+		{
+			"strict",
+			[]string{"/name", "/spec/containers/5"},
+			ProjectOptions{Strict: true},
+			nil,
+			ErrOutOfRange,
+		},
+		{
+			"root",
+			[]string{""},
+			ProjectOptions{},
+			doc,
+			nil,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			ptrs := make([]*Pointer, len(tc.Pointers))
+			for i, p := range tc.Pointers {
+				ptrs[i] = MustParse(p)
+			}
+
+			actual, err := ProjectWithOptions(doc, ptrs, tc.Opts)
+			if !errors.Is(err, tc.Err) {
+				t.Fatalf("expected %v, got: %v", tc.Err, err)
+			}
+			if err != nil {
+				return
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
+
+func TestProject_copies(t *testing.T) {
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1},
+	}
+
+	actual, err := Project(doc, []*Pointer{MustParse("/a")})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual.(map[string]interface{})["a"].(map[string]interface{})["b"] = 2
+	if doc["a"].(map[string]interface{})["b"] != 1 {
+		t.Fatalf("input modified: %#v", doc)
+	}
+}
@@ -0,0 +1,106 @@
+package pointerstructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// decodeRawJSON decodes v if it is a json.RawMessage, so that the following
+// parts of a pointer can be resolved within it. Other values are returned
+// as-is.
+func decodeRawJSON(v reflect.Value) (reflect.Value, error) {
+	raw, ok := asRawJSON(v)
+	if !ok {
+		return v, nil
+	}
+
+	result, err := unmarshalRawJSON(raw)
+	if err != nil {
+		return v, fmt.Errorf("decoding json.RawMessage: %w", err)
+	}
+
+	return reflect.ValueOf(&result).Elem(), nil
+}
+
+// unmarshalRawJSON decodes raw like json.Unmarshal, but decodes numbers as
+// json.Number so that they are encoded again exactly as they were.
+func unmarshalRawJSON(raw json.RawMessage) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var result interface{}
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level value")
+	}
+
+	return result, nil
+}
+
+// asRawJSON returns the json.RawMessage that v holds, if any.
+func asRawJSON(v reflect.Value) (json.RawMessage, bool) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if !v.IsValid() || v.Type() != rawMessageType || !v.CanInterface() {
+		return nil, false
+	}
+
+	return v.Interface().(json.RawMessage), true
+}
+
+// modifyRawJSON implements Set and Delete for pointers that go through a
+// json.RawMessage when Config.DecodeRawJSON is set. The nearest
+// json.RawMessage above the value is decoded, modified with the remaining
+// parts of the pointer using fn, and then encoded and written back in
+// place of the original json.RawMessage.
+//
+// If the pointer doesn't go through a json.RawMessage, ok is false.
+func (p *Pointer) modifyRawJSON(
+	s interface{},
+	fn func(sub *Pointer, doc interface{}) (interface{}, error),
+) (result interface{}, ok bool, err error) {
+	for i := len(p.Parts) - 1; i >= 0; i-- {
+		prefix := &Pointer{Parts: p.Parts[:i], Config: p.Config}
+		v, _, err := prefix.get(s)
+		if err != nil {
+			continue
+		}
+
+		raw, ok := asRawJSON(v)
+		if !ok {
+			continue
+		}
+
+		doc, err := unmarshalRawJSON(raw)
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: decoding json.RawMessage: %w", prefix, err)
+		}
+
+		sub := &Pointer{Parts: p.Parts[i:], Config: p.Config}
+		doc, err = fn(sub, doc)
+		if err != nil {
+			return nil, true, err
+		}
+
+		encoded, err := json.Marshal(doc)
+		if err != nil {
+			return nil, true, fmt.Errorf("%s: encoding json.RawMessage: %w", prefix, err)
+		}
+
+		result, err := prefix.Set(s, json.RawMessage(encoded))
+		return result, true, err
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,133 @@
+package pointerstructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type testEnvelope struct {
+	Kind    string          `pointer:"kind"`
+	Payload json.RawMessage `pointer:"payload"`
+}
+
+func TestPointerGet_rawJSON(t *testing.T) {
+	doc := &testEnvelope{
+		Kind:    "list",
+		Payload: json.RawMessage(`[{"name": "a"}, {"name": "b"}]`),
+	}
+
+	cases := []struct {
+		Pointer string
+		Decode  bool
+		Output  interface{}
+	}{
+		{"/payload/0", false, byte('[')},
+		{"/payload/0", true, map[string]interface{}{"name": "a"}},
+		{"/payload/1/name", true, "b"},
+		{"/payload", true, doc.Payload},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.DecodeRawJSON = tc.Decode
+			actual, err := p.Get(doc)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
+
+func TestPointerGet_rawJSONInvalid(t *testing.T) {
+	p := MustParse("/payload/0")
+	p.Config.DecodeRawJSON = true
+	if _, err := p.Get(&testEnvelope{Payload: json.RawMessage(`[`)}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPointerSet_rawJSON(t *testing.T) {
+	cases := []struct {
+		Pointer string
+		Delete  bool
+		Value   interface{}
+		Output  string
+	}{
+		{"/payload/1/name", false, "c", `[{"name":"a"},{"name":"c"}]`},
+		{"/payload/-", false, 1, `[{"name":"a"},{"name":"b"},1]`},
+		{"/payload/0", true, nil, `[{"name":"b"}]`},
+		{"/payload/0/name", true, nil, `[{},{"name":"b"}]`},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			doc := &testEnvelope{
+				Payload: json.RawMessage(`[{"name": "a"}, {"name": "b"}]`),
+			}
+
+			p := MustParse(tc.Pointer)
+			p.Config.DecodeRawJSON = true
+
+			var err error
+			if tc.Delete {
+				_, err = p.Delete(doc)
+			} else {
+				_, err = p.Set(doc, tc.Value)
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if string(doc.Payload) != tc.Output {
+				t.Fatalf("bad: %s", doc.Payload)
+			}
+		})
+	}
+}
+
+func TestPointerSet_rawJSONRoot(t *testing.T) {
+	p := MustParse("/a")
+	p.Config.DecodeRawJSON = true
+	actual, err := p.Set(json.RawMessage(`{"a": 1}`), 2)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if string(actual.(json.RawMessage)) != `{"a":2}` {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestPointerSet_rawJSONNumbers(t *testing.T) {
+	doc := &testEnvelope{
+		Payload: json.RawMessage(`{"big": 12345678901234567890, "price": 1.50, "name": "a"}`),
+	}
+
+	p := MustParse("/payload/name")
+	p.Config.DecodeRawJSON = true
+	if _, err := p.Set(doc, "b"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := `{"big":12345678901234567890,"name":"b","price":1.50}`
+	if string(doc.Payload) != expected {
+		t.Fatalf("bad: %s", doc.Payload)
+	}
+
+	p = MustParse("/payload/big")
+	p.Config.DecodeRawJSON = true
+	actual, err := p.Get(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != json.Number("12345678901234567890") {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
@@ -0,0 +1,330 @@
+package pointerstructure
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Redact returns a deep copy of v with the values at the pointers matching
+// patterns replaced by replacement. A "*" part in a pattern matches any
+// single part, so "/users/*/password" matches the password of every user.
+// A value referenced from multiple places in v is copied for each place, so
+// that it is redacted by the patterns matching each of its pointers.
+//
+// Struct fields with the "secret" tag option, such as
+// `pointer:"token,secret"`, are redacted as well.
+//
+// The replacement is converted to the type of each value it replaces,
+// like Set. If it can't be converted, for example a string replacing an
+// int, the zero value of the type is used instead.
+func Redact(v interface{}, patterns []string, replacement interface{}) (interface{}, error) {
+	r := &redactor{
+		replacement: reflect.ValueOf(replacement),
+		refs:        map[cloneKey]int{},
+		active:      map[cloneKey]bool{},
+	}
+
+	for _, pattern := range patterns {
+		p, err := Parse(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		r.patterns = append(r.patterns, p.Parts)
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	if r.matches(nil) {
+		return replacement, nil
+	}
+
+	copied, err := Clone(v)
+	if err != nil {
+		return nil, err
+	}
+
+	r.count(reflect.ValueOf(copied))
+	result, err := r.redact(reflect.ValueOf(copied), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Interface(), nil
+}
+
+type redactor struct {
+	config      Config
+	patterns    [][]string
+	replacement reflect.Value
+
+	// refs is the number of references to each pointer, map and slice in
+	// the copy, and active is those being redacted, since the copy may
+	// contain shared values and cycles.
+	refs   map[cloneKey]int
+	active map[cloneKey]bool
+}
+
+// redact redacts the children of v, which is at the pointer with the given
+// parts, and returns the value to store in place of v. Since v is a copy,
+// it is modified in place where possible.
+func (r *redactor) redact(v reflect.Value, parts []string) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		elem, err := r.redact(v.Elem(), parts)
+		if err != nil {
+			return v, err
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		result.Set(elem)
+		return result, nil
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		var leave func()
+		var err error
+		v, leave, err = r.enter(v)
+		if err != nil || leave == nil {
+			return v, err
+		}
+		defer leave()
+
+		elem, err := r.redact(v.Elem(), parts)
+		if err != nil {
+			return v, err
+		}
+
+		v.Elem().Set(elem)
+		return v, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		var leave func()
+		var err error
+		v, leave, err = r.enter(v)
+		if err != nil || leave == nil {
+			return v, err
+		}
+		defer leave()
+
+		for _, k := range v.MapKeys() {
+			part := fmt.Sprint(k.Interface())
+			if k.Kind() == reflect.String {
+				part = k.String()
+			}
+
+			elem, err := r.child(v.MapIndex(k), v.Type().Elem(), parts, part, false)
+			if err != nil {
+				return v, err
+			}
+
+			v.SetMapIndex(k, elem)
+		}
+
+		return v, nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Array {
+			// Elements of an array are only settable if it is addressable
+			result := reflect.New(v.Type()).Elem()
+			result.Set(v)
+			v = result
+		} else if !v.IsNil() {
+			var leave func()
+			var err error
+			v, leave, err = r.enter(v)
+			if err != nil || leave == nil {
+				return v, err
+			}
+			defer leave()
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			elem, err := r.child(v.Index(i), v.Type().Elem(), parts, fmt.Sprint(i), false)
+			if err != nil {
+				return v, err
+			}
+
+			v.Index(i).Set(elem)
+		}
+
+		return v, nil
+
+	case reflect.Struct:
+		fields, err := cachedStructFields(v.Type(), r.config.tagName())
+		if err != nil {
+			return v, err
+		}
+
+		// Fields are only settable if the struct is addressable
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+		v = result
+
+		for _, f := range fields.list {
+			// Fields of nil embedded structs don't exist, and fields
+			// promoted from unexported embedded structs can't be set
+			fieldVal, err := fieldByIndex(v, f.index)
+			if err != nil || !fieldVal.CanSet() {
+				continue
+			}
+
+			elem, err := r.child(fieldVal, f.typ, parts, f.name, f.secret)
+			if err != nil {
+				return v, err
+			}
+
+			fieldVal.Set(elem)
+		}
+
+		return v, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// child returns the value to store in place of the child v of type typ
+// with the given part, which is replaced if it matches or is secret.
+func (r *redactor) child(v reflect.Value, typ reflect.Type, parts []string, part string, secret bool) (reflect.Value, error) {
+	childParts := make([]string, len(parts), len(parts)+1)
+	copy(childParts, parts)
+	childParts = append(childParts, part)
+
+	if secret || r.matches(childParts) {
+		return r.replacementFor(typ), nil
+	}
+
+	return r.redact(v, childParts)
+}
+
+// matches returns true if the pointer with the given parts matches any of
+// the patterns.
+func (r *redactor) matches(parts []string) bool {
+	for _, pattern := range r.patterns {
+		if len(pattern) != len(parts) {
+			continue
+		}
+
+		match := true
+		for i, part := range pattern {
+			if part != "*" && part != parts[i] {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+
+	return false
+}
+
+// replacementFor returns the replacement converted to the type typ, or the
+// zero value if it can't be converted.
+func (r *redactor) replacementFor(typ reflect.Type) reflect.Value {
+	result, err := r.config.coerceValue(r.replacement, typ)
+	if err != nil {
+		return reflect.Zero(typ)
+	}
+
+	return result
+}
+
+// enter starts redacting the pointer, map or slice v, returning the value
+// to redact in its place and a function to call when done. Since the same
+// value can be reached by different paths that match different patterns,
+// a value with multiple references is copied so that each path is redacted
+// separately. If v is already being redacted because it contains itself,
+// leave is nil and v shouldn't be redacted again.
+func (r *redactor) enter(v reflect.Value) (result reflect.Value, leave func(), err error) {
+	key := r.key(v)
+	if r.active[key] {
+		return v, nil, nil
+	}
+
+	if r.refs[key] > 1 {
+		copied, err := Clone(v.Interface())
+		if err != nil {
+			return v, nil, err
+		}
+
+		v = reflect.ValueOf(copied)
+		key = r.key(v)
+		r.count(v)
+	}
+
+	r.active[key] = true
+	return v, func() { delete(r.active, key) }, nil
+}
+
+// count counts the references to the pointers, maps and slices within v.
+func (r *redactor) count(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			r.count(v.Elem())
+		}
+
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+
+		key := r.key(v)
+		r.refs[key]++
+		if r.refs[key] > 1 {
+			return
+		}
+
+		switch v.Kind() {
+		case reflect.Ptr:
+			r.count(v.Elem())
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				r.count(iter.Value())
+			}
+		default:
+			r.countElems(v)
+		}
+
+	case reflect.Array:
+		r.countElems(v)
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			r.count(v.Field(i))
+		}
+	}
+}
+
+func (r *redactor) countElems(v reflect.Value) {
+	for i := 0; i < v.Len(); i++ {
+		r.count(v.Index(i))
+	}
+}
+
+func (r *redactor) key(v reflect.Value) cloneKey {
+	key := cloneKey{typ: v.Type(), ptr: v.Pointer()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+
+	return key
+}
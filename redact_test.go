@@ -0,0 +1,189 @@
+package pointerstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	doc := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"token": "abc",
+			"user":  "bob",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "a", "password": "x"},
+			map[string]interface{}{"name": "b", "password": "y"},
+		},
+		"ports": map[int]int{80: 1, 443: 2},
+	}
+
+	actual, err := Redact(doc, []string{"/auth/token", "/users/*/password", "/ports/443", "/missing/*"}, "REDACTED")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"token": "REDACTED",
+			"user":  "bob",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"name": "a", "password": "REDACTED"},
+			map[string]interface{}{"name": "b", "password": "REDACTED"},
+		},
+		// The replacement can't be converted to an int
+		"ports": map[int]int{80: 1, 443: 0},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// The input is unmodified
+	if doc["auth"].(map[string]interface{})["token"] != "abc" {
+		t.Fatalf("input modified: %#v", doc)
+	}
+}
+
+func TestRedact_struct(t *testing.T) {
+	type credentials struct {
+		Token  string `pointer:"token,secret"`
+		Region string
+	}
+	type embedded struct {
+		Key string `pointer:",secret"`
+	}
+	type config struct {
+		embedded
+		Name     string
+		Creds    *credentials
+		Backends map[string]credentials
+		Codes    [2]int `pointer:"codes,secret"`
+	}
+
+	doc := &config{
+		embedded: embedded{Key: "k"},
+		Name:     "web",
+		Creds:    &credentials{Token: "t", Region: "eu"},
+		Backends: map[string]credentials{
+			"a": {Token: "t", Region: "us"},
+		},
+		Codes: [2]int{1, 2},
+	}
+
+	actual, err := Redact(doc, []string{"/Name"}, "***")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := &config{
+		embedded: embedded{Key: "***"},
+		Name:     "***",
+		Creds:    &credentials{Token: "***", Region: "eu"},
+		Backends: map[string]credentials{
+			"a": {Token: "***", Region: "us"},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if doc.Key != "k" || doc.Creds.Token != "t" || doc.Backends["a"].Token != "t" || doc.Codes[0] != 1 {
+		t.Fatalf("input modified: %#v", doc)
+	}
+}
+
+func TestRedact_cycle(t *testing.T) {
+	type node struct {
+		Secret string `pointer:"secret,secret"`
+		Next   *node
+	}
+
+	n := &node{Secret: "s"}
+	n.Next = n
+
+	actual, err := Redact(n, nil, "")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := actual.(*node)
+	if result.Secret != "" || result.Next != result {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestRedact_shared(t *testing.T) {
+	creds := map[string]interface{}{"user": "u", "password": "p"}
+	tags := []interface{}{"a", "b"}
+	input := map[string]interface{}{
+		"users":  map[string]interface{}{"a": creds, "b": creds, "c": creds},
+		"shared": creds,
+		"tags":   map[string]interface{}{"x": tags, "y": tags},
+	}
+
+	// Map iteration order is random, so try it a few times
+	for i := 0; i < 20; i++ {
+		actual, err := Redact(input, []string{"/users/*/password", "/tags/x/0"}, "REDACTED")
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		redacted := map[string]interface{}{"user": "u", "password": "REDACTED"}
+		expected := map[string]interface{}{
+			"users": map[string]interface{}{
+				"a": redacted,
+				"b": redacted,
+				"c": redacted,
+			},
+			"shared": creds,
+			"tags": map[string]interface{}{
+				"x": []interface{}{"REDACTED", "b"},
+				"y": []interface{}{"a", "b"},
+			},
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("bad: %#v", actual)
+		}
+	}
+
+	if creds["password"] != "p" || tags[0] != "a" {
+		t.Fatal("input modified")
+	}
+}
+
+func TestRedact_unexportedEmbeddedPointer(t *testing.T) {
+	type creds struct {
+		Token string `pointer:"token,secret"`
+	}
+	type req struct {
+		*creds
+	}
+
+	input := &req{&creds{Token: "t"}}
+	actual, err := Redact(input, nil, "REDACTED")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if actual.(*req).Token != "REDACTED" {
+		t.Fatalf("bad: %#v", actual.(*req).creds)
+	}
+	if input.Token != "t" {
+		t.Fatalf("input modified: %#v", input.creds)
+	}
+}
+
+func TestRedact_root(t *testing.T) {
+	actual, err := Redact("secret", []string{""}, "REDACTED")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual != "REDACTED" {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	if _, err := Redact(nil, []string{"bad"}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,430 @@
+package pointerstructure
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// RefOptions configures how ResolveRefs resolves references.
+type RefOptions struct {
+	// Config is the configuration used to resolve the pointers in
+	// references.
+	Config Config
+
+	// IgnoreCycles makes references that would inline a value within
+	// itself stay in place rather than returning an error wrapping
+	// ErrRefCycle.
+	IgnoreCycles bool
+
+	// Loader loads the documents that external references, such as
+	// "common.json#/Error", refer to. If this is nil, only local
+	// references are supported.
+	Loader Loader
+
+	// BaseURI is the URI of the document being resolved. Relative URIs of
+	// external references are resolved against the URI of the document
+	// containing the reference, starting with this.
+	BaseURI string
+}
+
+// ResolveRefs returns a copy of doc with JSON Schema and OpenAPI style
+// references, objects with a "$ref" key such as
+// {"$ref": "#/components/schemas/Pet"}, replaced by the value they refer to.
+// Any other keys of the reference object are dropped. References within the
+// referenced values are resolved as well.
+//
+// Local references are URI fragment pointers into doc. External references
+// are loaded with the Loader in the options, and each document is loaded
+// only once. Maps and slices are copied, the input document is unmodified.
+//
+// If a reference can't be resolved, the error includes the chain of
+// references that led to it.
+func ResolveRefs(doc interface{}, opts RefOptions) (interface{}, error) {
+	r := &refResolver{
+		opts:  opts,
+		base:  refDoc{uri: opts.BaseURI, doc: doc},
+		cache: map[string]interface{}{opts.BaseURI: doc},
+	}
+
+	result, err := r.resolve(reflect.ValueOf(doc))
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.IsValid() {
+		return nil, nil
+	}
+
+	return result.Interface(), nil
+}
+
+// refResolver is the state of ResolveRefs.
+type refResolver struct {
+	opts RefOptions
+
+	// base is the document that references are currently relative to.
+	base refDoc
+
+	// cache is the documents loaded so far by URI.
+	cache map[string]interface{}
+
+	// chain is the absolute references currently being resolved, to
+	// detect cycles.
+	chain []string
+}
+
+// refDoc is a document along with the URI it was loaded from.
+type refDoc struct {
+	uri string
+	doc interface{}
+}
+
+func (r *refResolver) resolve(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		elem, err := r.resolve(v.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		if err := setResolved(result, elem); err != nil {
+			return reflect.Value{}, err
+		}
+		return result, nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		if ref, ok := refOf(v); ok {
+			return r.follow(ref, v)
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := r.resolve(iter.Value())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := setResolved(value, elem); err != nil {
+				return reflect.Value{}, err
+			}
+			result.SetMapIndex(iter.Key(), value)
+		}
+
+		return result, nil
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := r.resolve(v.Index(i))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			if err := setResolved(result.Index(i), elem); err != nil {
+				return reflect.Value{}, err
+			}
+		}
+
+		return result, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// follow resolves the reference ref found in the node v.
+func (r *refResolver) follow(ref string, v reflect.Value) (reflect.Value, error) {
+	uri, fragment, err := r.absolute(r.base.uri, ref)
+	if err != nil {
+		return reflect.Value{}, r.chainError(ref, err)
+	}
+
+	abs := uri + fragment
+	for _, c := range r.chain {
+		if c == abs {
+			if r.opts.IgnoreCycles {
+				return v, nil
+			}
+
+			return reflect.Value{}, refCycleError(append(r.chain, abs))
+		}
+	}
+
+	target, err := r.lookup(uri, fragment, []string{abs})
+	if err != nil {
+		return reflect.Value{}, r.chainError(abs, err)
+	}
+
+	// References within the target are relative to its document
+	base := r.base
+	r.base = target.refDoc
+	r.chain = append(r.chain, abs)
+	defer func() {
+		r.base = base
+		r.chain = r.chain[:len(r.chain)-1]
+	}()
+
+	return r.resolve(target.value)
+}
+
+// refTarget is a value that a reference refers to, along with the document
+// it was found in.
+type refTarget struct {
+	refDoc
+	value reflect.Value
+}
+
+// lookup returns the value that fragment refers to in the document with the
+// given URI. References along the path are followed and may lead to other
+// documents, but a reference at the target itself is returned as is so that
+// resolve can follow it. seen is the references followed so far.
+func (r *refResolver) lookup(uri, fragment string, seen []string) (refTarget, error) {
+	doc, err := r.load(uri)
+	if err != nil {
+		return refTarget{}, err
+	}
+
+	p, err := ParseURIFragment(fragment)
+	if err != nil {
+		return refTarget{}, err
+	}
+
+	// References are followed here rather than by the pointer, since the
+	// pointer only sees the current value rather than the whole document
+	config := r.opts.Config
+	config.FollowRefs = false
+
+	target := refTarget{refDoc: refDoc{uri: uri, doc: doc}, value: reflect.ValueOf(doc)}
+	for i, part := range p.Parts {
+		if ref, ok := refOf(target.value); ok {
+			target, err = r.lookupRef(target.refDoc, ref, seen)
+			if err != nil {
+				return refTarget{}, fmt.Errorf("%s at part %d: %w", p, i, err)
+			}
+		}
+
+		var current interface{}
+		if target.value.IsValid() {
+			current = target.value.Interface()
+		}
+
+		step := &Pointer{Parts: []string{part}, Config: config}
+		target.value, _, err = step.get(current)
+		if err != nil {
+			return refTarget{}, fmt.Errorf("%s at part %d: %w", p, i, err)
+		}
+	}
+
+	return target, nil
+}
+
+// lookupRef looks up the reference ref found along a path in the document
+// base.
+func (r *refResolver) lookupRef(base refDoc, ref string, seen []string) (refTarget, error) {
+	uri, fragment, err := r.absolute(base.uri, ref)
+	if err != nil {
+		return refTarget{}, fmt.Errorf("resolving $ref %q: %w", ref, err)
+	}
+
+	abs := uri + fragment
+	for _, s := range seen {
+		if s == abs {
+			return refTarget{}, refCycleError(append(seen, abs))
+		}
+	}
+
+	// Copy so that following other references doesn't share seen
+	next := make([]string, len(seen), len(seen)+1)
+	copy(next, seen)
+
+	target, err := r.lookup(uri, fragment, append(next, abs))
+	if err != nil {
+		return refTarget{}, fmt.Errorf("resolving $ref %q: %w", ref, err)
+	}
+
+	return target, nil
+}
+
+// absolute splits ref into the URI of the document it refers to, resolved
+// against the base URI, and the fragment pointer.
+func (r *refResolver) absolute(base, ref string) (uri, fragment string, err error) {
+	fragment = "#"
+	if idx := strings.IndexByte(ref, '#'); idx >= 0 {
+		ref, fragment = ref[:idx], ref[idx:]
+	}
+
+	// Local reference
+	if ref == "" {
+		return base, fragment, nil
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", "", err
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", "", err
+	}
+
+	// url.URL.ResolveReference makes relative paths absolute, so relative
+	// paths are joined as paths instead.
+	if refURL.Scheme == "" && refURL.Host == "" &&
+		baseURL.Scheme == "" && baseURL.Host == "" &&
+		!strings.HasPrefix(baseURL.Path, "/") {
+		if strings.HasPrefix(refURL.Path, "/") {
+			return refURL.Path, fragment, nil
+		}
+
+		return path.Join(path.Dir(baseURL.Path), refURL.Path), fragment, nil
+	}
+
+	return baseURL.ResolveReference(refURL).String(), fragment, nil
+}
+
+// load returns the document with the given URI, loading it if necessary.
+func (r *refResolver) load(uri string) (interface{}, error) {
+	if doc, ok := r.cache[uri]; ok {
+		return doc, nil
+	}
+
+	if r.opts.Loader == nil {
+		return nil, fmt.Errorf("no Loader to load external document %q", uri)
+	}
+
+	doc, err := r.opts.Loader.Load(uri)
+	if err != nil {
+		return nil, fmt.Errorf("loading %q: %w", uri, err)
+	}
+
+	r.cache[uri] = doc
+	return doc, nil
+}
+
+// chainError wraps err with the chain of references that led to ref.
+func (r *refResolver) chainError(ref string, err error) error {
+	chain := append(append([]string{}, r.chain...), ref)
+	return fmt.Errorf("resolving $ref %s: %w", strings.Join(chain, " -> "), err)
+}
+
+// resolveRef returns the value that the local reference ref refers to
+// in the document root. chain is the references being followed, including
+// ref, to detect cycles.
+func (c Config) resolveRef(root interface{}, ref string, chain []string) (reflect.Value, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return reflect.Value{}, fmt.Errorf("only local references are supported")
+	}
+
+	target, err := ParseURIFragment(ref)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	target.Config = c
+
+	v, _, err := target.getFollowing(root, chain)
+	return v, err
+}
+
+// followRefs follows v while it is a reference, returning the value it
+// ultimately refers to. chain is the references already being followed.
+func (p *Pointer) followRefs(root interface{}, v reflect.Value, chain []string) (reflect.Value, error) {
+	for {
+		ref, ok := refOf(v)
+		if !ok {
+			return v, nil
+		}
+
+		for _, c := range chain {
+			if c == ref {
+				return reflect.Value{}, refCycleError(append(chain, ref))
+			}
+		}
+
+		// Copy so that following other references doesn't share the chain
+		next := make([]string, len(chain), len(chain)+1)
+		copy(next, chain)
+		chain = append(next, ref)
+
+		var err error
+		v, err = p.Config.resolveRef(root, ref, chain)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("resolving $ref %q: %w", ref, err)
+		}
+	}
+}
+
+// refOf returns the reference that v is, if it is a map with a string
+// "$ref" key.
+func refOf(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Map || v.IsNil() {
+		return "", false
+	}
+
+	keyType := v.Type().Key()
+	var key reflect.Value
+	switch keyType.Kind() {
+	case reflect.String:
+		key = reflect.ValueOf("$ref").Convert(keyType)
+	case reflect.Interface:
+		key = reflect.ValueOf("$ref")
+		if !key.Type().AssignableTo(keyType) {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	ref := v.MapIndex(key)
+	for ref.Kind() == reflect.Interface {
+		ref = ref.Elem()
+	}
+	if ref.Kind() != reflect.String {
+		return "", false
+	}
+
+	return ref.String(), true
+}
+
+// setResolved sets dst to the resolved value v, which may have a
+// different type than the value it replaces.
+func setResolved(dst, v reflect.Value) error {
+	if !v.IsValid() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	if !v.Type().AssignableTo(dst.Type()) {
+		return fmt.Errorf(
+			"%w %#v to type %s", ErrConvert, v.Interface(), dst.Type())
+	}
+
+	dst.Set(v)
+	return nil
+}
+
+func refCycleError(chain []string) error {
+	return fmt.Errorf("%w: %s", ErrRefCycle, strings.Join(chain, " -> "))
+}
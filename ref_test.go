@@ -0,0 +1,318 @@
+package pointerstructure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testDecodeJSON(t *testing.T, raw string) interface{} {
+	t.Helper()
+
+	var result interface{}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return result
+}
+
+func TestResolveRefs(t *testing.T) {
+	cases := []struct {
+		Name   string
+		Input  string
+		Opts   RefOptions
+		Output string
+		Err    error
+	}{
+		{
+			"no refs",
+			`{"a": [1, {"b": 2}]}`,
+			RefOptions{},
+			`{"a": [1, {"b": 2}]}`,
+			nil,
+		},
+
+		{
+			"local ref",
+			`{
+				"paths": {"/pets": {"schema": {"$ref": "#/components/schemas/Pet"}}},
+				"components": {"schemas": {"Pet": {"type": "object"}}}
+			}`,
+			RefOptions{},
+			`{
+				"paths": {"/pets": {"schema": {"type": "object"}}},
+				"components": {"schemas": {"Pet": {"type": "object"}}}
+			}`,
+			nil,
+		},
+
+		{
+			"ref to ref",
+			`{"a": {"$ref": "#/b"}, "b": {"$ref": "#/c"}, "c": [1]}`,
+			RefOptions{},
+			`{"a": [1], "b": [1], "c": [1]}`,
+			nil,
+		},
+
+		{
+			"ref through ref",
+			`{"a": {"$ref": "#/b/x"}, "b": {"$ref": "#/c"}, "c": {"x": 1}}`,
+			RefOptions{},
+			`{"a": 1, "b": {"x": 1}, "c": {"x": 1}}`,
+			nil,
+		},
+
+		{
+			"ref through ref with FollowRefs",
+			`{"a": {"$ref": "#/b/c/x"}, "b": {"c": {"$ref": "#/d"}}, "d": {"x": 1}}`,
+			RefOptions{Config: Config{FollowRefs: true}},
+			`{"a": 1, "b": {"c": {"x": 1}}, "d": {"x": 1}}`,
+			nil,
+		},
+
+		{
+			"ref in array",
+			`{"a": [{"$ref": "#/b"}], "b": "x"}`,
+			RefOptions{},
+			`{"a": ["x"], "b": "x"}`,
+			nil,
+		},
+
+		{
+			"percent-encoded ref",
+			`{"a": {"$ref": "#/b%20c~1d"}, "b c/d": 1}`,
+			RefOptions{},
+			`{"a": 1, "b c/d": 1}`,
+			nil,
+		},
+
+		{
+			"missing target",
+			`{"a": {"$ref": "#/b"}}`,
+			RefOptions{},
+			"",
+			ErrNotFound,
+		},
+
+		{
+			"direct cycle",
+			`{"a": {"$ref": "#/b"}, "b": {"$ref": "#/a"}}`,
+			RefOptions{},
+			"",
+			ErrRefCycle,
+		},
+
+		{
+			"recursive schema",
+			`{"node": {"next": {"$ref": "#/node"}}}`,
+			RefOptions{},
+			"",
+			ErrRefCycle,
+		},
+
+		{
+			"recursive schema ignored",
+			`{"node": {"next": {"$ref": "#/node"}}}`,
+			RefOptions{IgnoreCycles: true},
+			`{"node": {"next": {"next": {"$ref": "#/node"}}}}`,
+			nil,
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			input := testDecodeJSON(t, tc.Input)
+			original := testDecodeJSON(t, tc.Input)
+
+			actual, err := ResolveRefs(input, tc.Opts)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			expected := testDecodeJSON(t, tc.Output)
+			if !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("bad: %#v", actual)
+			}
+
+			if !reflect.DeepEqual(input, original) {
+				t.Fatalf("input modified: %#v", input)
+			}
+		})
+	}
+}
+
+func TestPointerGet_followRefs(t *testing.T) {
+	doc := testDecodeJSON(t, `{
+		"paths": {"/pets": {"schema": {"$ref": "#/components/schemas/Pet"}}},
+		"components": {"schemas": {
+			"Pet": {"properties": {"id": {"type": "integer"}}},
+			"Loop": {"$ref": "#/components/schemas/Loop"}
+		}}
+	}`)
+
+	cases := []struct {
+		Pointer string
+		Follow  bool
+		Output  interface{}
+		Err     error
+	}{
+		{"/paths/~1pets/schema/properties/id/type", true, "integer", nil},
+		{"/paths/~1pets/schema/properties/id/type", false, nil, ErrNotFound},
+		{"/paths/~1pets/schema/$ref", false, "#/components/schemas/Pet", nil},
+		{"/components/schemas/Loop/x", true, nil, ErrRefCycle},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.FollowRefs = tc.Follow
+			actual, err := p.Get(doc)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+
+	// The final value is followed as well
+	p := MustParse("/paths/~1pets/schema")
+	p.Config.FollowRefs = true
+	actual, err := p.Get(doc)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := actual.(map[string]interface{})["properties"]; !ok {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestResolveRefs_external(t *testing.T) {
+	loads := map[string]int{}
+	docs := MapLoader{
+		"specs/common.json": testDecodeJSON(t, `{
+			"Error": {"properties": {"code": {"$ref": "#/Code"}}},
+			"Code": {"type": "integer"},
+			"Nested": {"$ref": "types/id.json#/ID"}
+		}`),
+		"specs/types/id.json": testDecodeJSON(t, `{"ID": {"type": "string"}}`),
+	}
+	loader := LoaderFunc(func(uri string) (interface{}, error) {
+		loads[uri]++
+		return docs.Load(uri)
+	})
+
+	input := testDecodeJSON(t, `{
+		"a": {"$ref": "common.json#/Error"},
+		"b": {"$ref": "common.json#/Code"},
+		"c": {"$ref": "common.json#/Nested"},
+		"d": {"$ref": "#/b"}
+	}`)
+
+	actual, err := ResolveRefs(input, RefOptions{
+		Loader:  loader,
+		BaseURI: "specs/api.json",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := testDecodeJSON(t, `{
+		"a": {"properties": {"code": {"type": "integer"}}},
+		"b": {"type": "integer"},
+		"c": {"type": "string"},
+		"d": {"type": "integer"}
+	}`)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// Each document is loaded once
+	expectedLoads := map[string]int{
+		"specs/common.json":   1,
+		"specs/types/id.json": 1,
+	}
+	if !reflect.DeepEqual(loads, expectedLoads) {
+		t.Fatalf("bad loads: %#v", loads)
+	}
+}
+
+func TestResolveRefs_externalErrors(t *testing.T) {
+	loader := MapLoader{
+		"common.json": testDecodeJSON(t, `{"Error": {"$ref": "#/Missing"}, "Loop": {"$ref": "other.json"}}`),
+		"other.json":  testDecodeJSON(t, `{"$ref": "common.json#/Loop"}`),
+	}
+
+	cases := []struct {
+		Name   string
+		Input  string
+		Loader Loader
+		Err    error
+		Msg    string
+	}{
+		{
+			"no loader",
+			`{"a": {"$ref": "common.json#/Error"}}`,
+			nil,
+			nil,
+			"common.json#/Error",
+		},
+
+		{
+			"missing document",
+			`{"a": {"$ref": "nope.json#/Error"}}`,
+			loader,
+			ErrNotFound,
+			"nope.json#/Error",
+		},
+
+		{
+			"missing target in external document",
+			`{"a": {"$ref": "common.json#/Error"}}`,
+			loader,
+			ErrNotFound,
+			"common.json#/Error -> common.json#/Missing",
+		},
+
+		{
+			"cycle across documents",
+			`{"a": {"$ref": "common.json#/Loop"}}`,
+			loader,
+			ErrRefCycle,
+			"common.json#/Loop -> other.json# -> common.json#/Loop",
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			_, err := ResolveRefs(testDecodeJSON(t, tc.Input), RefOptions{Loader: tc.Loader})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tc.Err != nil && !errors.Is(err, tc.Err) {
+				t.Fatalf("expected %s, got: %v", tc.Err, err)
+			}
+			if !strings.Contains(err.Error(), tc.Msg) {
+				t.Fatalf("expected %q in error: %s", tc.Msg, err)
+			}
+		})
+	}
+}
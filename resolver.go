@@ -0,0 +1,80 @@
+package pointerstructure
+
+import (
+	"reflect"
+)
+
+// PointerGetter is implemented by types that resolve their own children
+// rather than being traversed with reflection. This is useful for types
+// such as ordered maps or lazily loaded values that aren't maps, slices,
+// or structs.
+//
+// PointerGet returns the child value addressed by part. An error
+// wrapping ErrNotFound should be returned if the child doesn't exist.
+type PointerGetter interface {
+	PointerGet(part string) (interface{}, error)
+}
+
+// PointerSetter is implemented by types that set their own children
+// rather than being modified with reflection. The value is given as-is,
+// it is up to the implementation to convert it if necessary.
+type PointerSetter interface {
+	PointerSet(part string, value interface{}) error
+}
+
+// PointerDeleter is implemented by types that delete their own children
+// rather than being modified with reflection.
+type PointerDeleter interface {
+	PointerDelete(part string) error
+}
+
+var (
+	pointerGetterType  = reflect.TypeOf((*PointerGetter)(nil)).Elem()
+	pointerSetterType  = reflect.TypeOf((*PointerSetter)(nil)).Elem()
+	pointerDeleterType = reflect.TypeOf((*PointerDeleter)(nil)).Elem()
+)
+
+// indirectResolver dereferences interfaces and pointers in v until it finds
+// a value implementing the interface type iface. If it finds one, the value
+// is returned as that interface. Otherwise the fully dereferenced value is
+// returned along with a nil interface.
+func indirectResolver(v reflect.Value, iface reflect.Type) (reflect.Value, interface{}) {
+	for {
+		if r := asResolver(v, iface); r != nil {
+			return v, r
+		}
+
+		switch v.Kind() {
+		case reflect.Interface:
+			v = v.Elem()
+
+		case reflect.Ptr:
+			v = reflect.Indirect(v)
+
+		default:
+			return v, nil
+		}
+	}
+}
+
+func asResolver(v reflect.Value, iface reflect.Type) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	// Don't call methods on nil pointers, they likely can't handle it
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+
+	if v.Type().Implements(iface) && v.CanInterface() {
+		return v.Interface()
+	}
+
+	// Methods with a pointer receiver can be used if the value is addressable
+	if v.Kind() != reflect.Ptr && v.CanAddr() && v.Addr().Type().Implements(iface) && v.Addr().CanInterface() {
+		return v.Addr().Interface()
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package pointerstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testOrderedMap is a map that remembers insertion order and resolves its
+// own children.
+type testOrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newTestOrderedMap(kv ...interface{}) *testOrderedMap {
+	m := &testOrderedMap{values: map[string]interface{}{}}
+	for i := 0; i < len(kv); i += 2 {
+		m.PointerSet(kv[i].(string), kv[i+1])
+	}
+
+	return m
+}
+
+func (m *testOrderedMap) PointerGet(part string) (interface{}, error) {
+	v, ok := m.values[part]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrNotFound, part)
+	}
+
+	return v, nil
+}
+
+func (m *testOrderedMap) PointerSet(part string, value interface{}) error {
+	if _, ok := m.values[part]; !ok {
+		m.keys = append(m.keys, part)
+	}
+
+	m.values[part] = value
+	return nil
+}
+
+func (m *testOrderedMap) PointerDelete(part string) error {
+	if _, ok := m.values[part]; !ok {
+		return fmt.Errorf("%w %q", ErrNotFound, part)
+	}
+
+	for i, k := range m.keys {
+		if k == part {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+
+	delete(m.values, part)
+	return nil
+}
+
+func TestPointerGet_resolver(t *testing.T) {
+	doc := map[string]interface{}{
+		"ordered": newTestOrderedMap(
+			"a", []interface{}{"x", "y"},
+			"b", newTestOrderedMap("c", 42),
+		),
+	}
+
+	cases := []struct {
+		Pointer string
+		Output  interface{}
+		Err     error
+	}{
+		{"/ordered/a/1", "y", nil},
+		{"/ordered/b/c", 42, nil},
+		{"/ordered/nope", nil, ErrNotFound},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			actual, err := Get(doc, tc.Pointer)
+			if tc.Err != nil {
+				if !errors.Is(err, tc.Err) {
+					t.Fatalf("expected %s, got: %v", tc.Err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
+
+func TestPointerSet_resolver(t *testing.T) {
+	m := newTestOrderedMap("a", 1)
+	doc := map[string]interface{}{"ordered": m}
+
+	if _, err := Set(doc, "/ordered/b", 2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(m.keys, []string{"a", "b"}) {
+		t.Fatalf("bad: %#v", m.keys)
+	}
+	if m.values["b"] != 2 {
+		t.Fatalf("bad: %#v", m.values)
+	}
+}
+
+func TestPointerDelete_resolver(t *testing.T) {
+	m := newTestOrderedMap("a", 1, "b", 2)
+	p := MustParse("/ordered/a")
+	if _, err := p.Delete(map[string]interface{}{"ordered": m}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(m.keys, []string{"b"}) {
+		t.Fatalf("bad: %#v", m.keys)
+	}
+
+	_, err := p.Delete(map[string]interface{}{"ordered": m})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got: %v", err)
+	}
+}
@@ -10,38 +10,53 @@ import (
 // The structures s must have non-zero values set up to this pointer.
 // For example, if setting "/bob/0/name", then "/bob/0" must be set already.
 //
+// Struct fields can only be set if the struct is addressable, for example
+// if s is a pointer to the struct. Structs and arrays stored in maps are
+// copied, modified and stored back into the map.
+//
+// If the parent of the pointer implements PointerSetter, it is used to set
+// the value rather than reflection.
+//
 // The returned value is potentially a new value if this pointer represents
-// the root document. Otherwise, the returned value will always be s.
+// the root document. Otherwise, the returned value will always be s, unless
+// Config.Immutable is set in which case it is a modified copy of s.
 func (p *Pointer) Set(s, v interface{}) (interface{}, error) {
 	// if we represent the root doc, return that
 	if len(p.Parts) == 0 {
 		return v, nil
 	}
 
-	// Save the original since this is going to be our return value
-	originalS := s
+	// Values within a json.RawMessage are modified in a decoded copy
+	if p.Config.DecodeRawJSON {
+		result, ok, err := p.modifyRawJSON(s, func(sub *Pointer, doc interface{}) (interface{}, error) {
+			return sub.Set(doc, v)
+		})
+		if ok {
+			return result, err
+		}
+	}
 
 	// Get the parent value
-	var err error
-	s, err = p.Parent().Get(s)
+	parent, err := p.parent(s)
 	if err != nil {
 		return nil, err
 	}
 
 	// Map for lookup of getter to call for type
 	funcMap := map[reflect.Kind]setFunc{
-		reflect.Array: p.setSlice,
-		reflect.Map:   p.setMap,
-		reflect.Slice: p.setSlice,
+		reflect.Array:  p.setSlice,
+		reflect.Map:    p.setMap,
+		reflect.Slice:  p.setSlice,
+		reflect.Struct: p.setStruct,
 	}
 
-	val := reflect.ValueOf(s)
-	for val.Kind() == reflect.Interface {
-		val = val.Elem()
-	}
+	val, r := indirectResolver(parent.value, pointerSetterType)
+	if setter, ok := r.(PointerSetter); ok {
+		if err := setter.PointerSet(p.Parts[len(p.Parts)-1], v); err != nil {
+			return nil, fmt.Errorf("set %s: %w", p, err)
+		}
 
-	for val.Kind() == reflect.Ptr {
-		val = reflect.Indirect(val)
+		return p.writeBack(s, parent, val, reflect.Value{})
 	}
 
 	f, ok := funcMap[val.Kind()]
@@ -49,74 +64,271 @@ func (p *Pointer) Set(s, v interface{}) (interface{}, error) {
 		return nil, fmt.Errorf("set %s: %w: %s", p, ErrInvalidKind, val.Kind())
 	}
 
-	result, err := f(originalS, val, reflect.ValueOf(v))
+	replaced, err := f(val, reflect.ValueOf(v))
 	if err != nil {
 		return nil, fmt.Errorf("set %s: %w", p, err)
 	}
 
-	return result, nil
+	return p.writeBack(s, parent, val, replaced)
 }
 
-type setFunc func(interface{}, reflect.Value, reflect.Value) (interface{}, error)
+// setFunc sets the value for the last part of the pointer in the container
+// s. If the container itself had to be replaced, such as when appending to
+// a slice, the new container is returned.
+type setFunc func(s, value reflect.Value) (reflect.Value, error)
+
+func (p *Pointer) setMap(m, value reflect.Value) (reflect.Value, error) {
+	var zeroValue reflect.Value
 
-func (p *Pointer) setMap(root interface{}, m, value reflect.Value) (interface{}, error) {
 	part := p.Parts[len(p.Parts)-1]
-	key, err := coerce(reflect.ValueOf(part), m.Type().Key())
+	key, _, err := p.mapKey(part, m)
 	if err != nil {
-		return root, err
+		return zeroValue, err
 	}
 
-	elem, err := coerce(value, m.Type().Elem())
+	elem, err := p.Config.coerceValue(value, m.Type().Elem())
 	if err != nil {
-		return root, err
+		return zeroValue, err
 	}
 
 	// Set the key
 	m.SetMapIndex(key, elem)
-	return root, nil
+	return zeroValue, nil
 }
 
-func (p *Pointer) setSlice(root interface{}, s, value reflect.Value) (interface{}, error) {
+func (p *Pointer) setSlice(s, value reflect.Value) (reflect.Value, error) {
+	var zeroValue reflect.Value
+
 	// Coerce the value, we'll need that no matter what
-	value, err := coerce(value, s.Type().Elem())
+	value, err := p.Config.coerceValue(value, s.Type().Elem())
 	if err != nil {
-		return root, err
+		return zeroValue, err
 	}
 
 	// If the part is the special "-", that means to append it (RFC6901 4.)
 	part := p.Parts[len(p.Parts)-1]
 	if part == "-" {
-		return p.setSliceAppend(root, s, value)
+		return p.setSliceAppend(s, value)
 	}
 
-	// Coerce the key to an int
-	idxVal, err := coerce(reflect.ValueOf(part), reflect.TypeOf(42))
+	idx, err := p.sliceIndex(part, s.Len())
 	if err != nil {
-		return root, err
-	}
-	idx := int(idxVal.Int())
-
-	// Verify we're within bounds
-	if idx < 0 || idx >= s.Len() {
-		return root, fmt.Errorf(
-			"index %d is %w (length = %d)", idx, ErrOutOfRange, s.Len())
+		return zeroValue, err
 	}
 
 	// Set the key
-	s.Index(idx).Set(value)
-	return root, nil
+	elem := s.Index(idx)
+	if !elem.CanSet() {
+		return zeroValue, fmt.Errorf("array index %d cannot be set", idx)
+	}
+	elem.Set(value)
+	return zeroValue, nil
 }
 
-func (p *Pointer) setSliceAppend(root interface{}, s, value reflect.Value) (interface{}, error) {
+func (p *Pointer) setSliceAppend(s, value reflect.Value) (reflect.Value, error) {
+	// The length of an array is fixed
+	if s.Kind() == reflect.Array {
+		return reflect.Value{}, fmt.Errorf(
+			"%w: cannot append to array %s", ErrInvalidKind, s.Type())
+	}
+
 	// Coerce the value, we'll need that no matter what. This should
 	// be a no-op since we expect it to be done already, but there is
 	// a fast-path check for that in coerce so do it anyways.
-	value, err := coerce(value, s.Type().Elem())
+	value, err := p.Config.coerceValue(value, s.Type().Elem())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// Append can return a new slice, so return it to be written back.
+	return reflect.Append(s, value), nil
+}
+
+func (p *Pointer) setStruct(s, value reflect.Value) (reflect.Value, error) {
+	var zeroValue reflect.Value
+
+	fields, err := cachedStructFields(s.Type(), p.Config.tagName())
+	if err != nil {
+		return zeroValue, err
+	}
+
+	part := p.Parts[len(p.Parts)-1]
+	f, err := p.structField(fields, part)
+	if err != nil {
+		return zeroValue, err
+	}
+
+	// When immutable, s is a copy but the structs it embeds by pointer are
+	// still shared, so those are copied as well
+	getField := fieldByIndex
+	if p.Config.Immutable {
+		getField = copyFieldByIndex
+	}
+
+	fieldVal, err := getField(s, f.index)
 	if err != nil {
-		return root, err
+		return zeroValue, err
+	}
+	if !fieldVal.CanSet() {
+		return zeroValue, fmt.Errorf("struct field %q cannot be set", part)
 	}
 
-	// We can assume "s" is the parent of pointer value. We need to actually
-	// write s back because Append can return a new slice.
-	return p.Parent().Set(root, reflect.Append(s, value).Interface())
+	value, err = p.Config.coerceValue(value, fieldVal.Type())
+	if err != nil {
+		return zeroValue, err
+	}
+
+	fieldVal.Set(value)
+	return zeroValue, nil
+}
+
+// parentValue is the parent of the value being modified by Set or Delete.
+type parentValue struct {
+	// value is the parent as returned by get.
+	value reflect.Value
+
+	// original is the parent before the ValueTransformationHook was applied.
+	original reflect.Value
+
+	// detached is true if value is a copy of the parent, which must be
+	// written back for the modification to be stored.
+	detached bool
+}
+
+// parent finds the parent of the value p points to within s, so that it
+// can be modified.
+func (p *Pointer) parent(s interface{}) (*parentValue, error) {
+	value, original, err := p.Parent().get(s)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &parentValue{value: value, original: original}
+
+	// When immutable, modify a copy of the parent rather than the parent
+	// itself. Writing back the copy copies the grandparent and so on up to
+	// the root.
+	//
+	// Structs and arrays within a map aren't addressable, so they are
+	// modified as a copy that is written back into the map as well.
+	if p.Config.Immutable || (len(p.Parts) > 1 && !canModify(value)) {
+		// The value of an interface isn't addressable, so copy the value
+		// itself rather than the interface.
+		for value.Kind() == reflect.Interface && !value.IsNil() {
+			value = value.Elem()
+		}
+
+		result.value = shallowCopy(value)
+		result.detached = true
+	}
+
+	return result, nil
+}
+
+// canModify reports whether the container v can be modified in place.
+// Maps and slices always can, while structs and arrays can only be modified
+// if they are addressable.
+func canModify(v reflect.Value) bool {
+	for v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Array, reflect.Struct:
+		return v.CanAddr()
+	default:
+		return true
+	}
+}
+
+// writeBack stores the modified parent of the pointer back into the
+// document s where that is necessary.
+//
+// val is the parent value with pointers and interfaces removed, which is
+// the container that was modified. If the container had to be replaced,
+// replaced is the new container.
+func (p *Pointer) writeBack(s interface{}, parent *parentValue, val, replaced reflect.Value) (interface{}, error) {
+	if replaced.IsValid() && val.CanSet() {
+		// The container is reached through a pointer so it can be
+		// replaced in place.
+		val.Set(replaced)
+		replaced = reflect.Value{}
+	}
+
+	changed := parent.detached || replaced.IsValid()
+	if !replaced.IsValid() {
+		replaced = parent.value
+	}
+
+	parentP := p.Parent()
+	if !parentP.IsRoot() &&
+		p.Config.ValueTransformationHook != nil &&
+		p.Config.ValueReverseTransformationHook != nil {
+		replaced = p.Config.ValueReverseTransformationHook(parent.original, replaced)
+		if replaced == reflect.ValueOf(nil) {
+			return nil, fmt.Errorf("%s: ValueReverseTransformationHook returned the value of a nil interface", p)
+		}
+
+		changed = true
+	}
+
+	if !changed {
+		return s, nil
+	}
+
+	return parentP.Set(s, replaced.Interface())
+}
+
+// shallowCopy returns a copy of v that shares everything but the top level
+// container with v. Pointers are copied along with the value they point to
+// so that the copy can be modified without affecting v.
+func shallowCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.New(v.Type()).Elem()
+		result.Set(shallowCopy(v.Elem()))
+		return result
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(shallowCopy(v.Elem()))
+		return result
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), iter.Value())
+		}
+		return result
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(result, v)
+		return result
+
+	case reflect.Array, reflect.Struct:
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+		return result
+
+	default:
+		return v
+	}
 }
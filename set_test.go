@@ -1,8 +1,10 @@
 package pointerstructure
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"testing"
 )
 
@@ -125,3 +127,397 @@ func TestPointerSet(t *testing.T) {
 		})
 	}
 }
+
+func TestPointerSet_caseInsensitive(t *testing.T) {
+	doc := map[string]interface{}{"Port": 80}
+	p := &Pointer{Parts: []string{"port"}, Config: Config{CaseInsensitive: true}}
+	if _, err := p.Set(doc, 8080); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{"Port": 8080}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("bad: %#v", doc)
+	}
+}
+
+func TestPointerSet_struct(t *testing.T) {
+	type inner struct {
+		Port int
+	}
+
+	doc := &struct {
+		Name  string `pointer:"name"`
+		Inner inner
+		Ptr   *inner
+	}{Ptr: &inner{}}
+
+	cases := []struct {
+		Pointer string
+		Value   interface{}
+	}{
+		{"/name", "foo"},
+		{"/Inner/Port", 80},
+		{"/Ptr/Port", "443"},
+	}
+
+	for _, tc := range cases {
+		if _, err := Set(doc, tc.Pointer, tc.Value); err != nil {
+			t.Fatalf("%s: %s", tc.Pointer, err)
+		}
+	}
+
+	if doc.Name != "foo" || doc.Inner.Port != 80 || doc.Ptr.Port != 443 {
+		t.Fatalf("bad: %#v", doc)
+	}
+
+	// A struct that isn't addressable can't be set
+	if _, err := Set(*doc, "/name", "bar"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestPointerSet_reverseHook(t *testing.T) {
+	type kv struct {
+		Key   string
+		Value interface{}
+	}
+	type wrapper struct {
+		Pairs []kv
+	}
+
+	// The hook presents a wrapper as a map, which is a copy, so changes
+	// are only stored if the reverse hook converts it back.
+	hook := func(v reflect.Value) reflect.Value {
+		if !v.CanInterface() {
+			return v
+		}
+		w, ok := v.Interface().(wrapper)
+		if !ok {
+			return v
+		}
+
+		m := map[string]interface{}{}
+		for _, p := range w.Pairs {
+			m[p.Key] = p.Value
+		}
+		return reflect.ValueOf(m)
+	}
+	reverse := func(original, transformed reflect.Value) reflect.Value {
+		if !original.CanInterface() {
+			return transformed
+		}
+		if _, ok := original.Interface().(wrapper); !ok {
+			return transformed
+		}
+
+		var w wrapper
+		iter := transformed.MapRange()
+		for iter.Next() {
+			w.Pairs = append(w.Pairs, kv{
+				Key:   iter.Key().String(),
+				Value: iter.Value().Interface(),
+			})
+		}
+		sort.Slice(w.Pairs, func(i, j int) bool { return w.Pairs[i].Key < w.Pairs[j].Key })
+		return reflect.ValueOf(w)
+	}
+
+	cases := []struct {
+		Name   string
+		Parts  []string
+		Set    bool
+		Value  interface{}
+		Output interface{}
+	}{
+		{
+			Name:  "set through wrapper",
+			Parts: []string{"w", "b"},
+			Set:   true,
+			Value: 2,
+			Output: map[string]interface{}{
+				"w": wrapper{Pairs: []kv{{"a", 1}, {"b", 2}}},
+			},
+		},
+		{
+			Name:  "set through nested wrapper",
+			Parts: []string{"w", "a", "c"},
+			Set:   true,
+			Value: 3,
+			Output: map[string]interface{}{
+				"w": wrapper{Pairs: []kv{{"a", wrapper{Pairs: []kv{{"c", 3}}}}}},
+			},
+		},
+		{
+			Name:  "delete through wrapper",
+			Parts: []string{"w", "a"},
+			Output: map[string]interface{}{
+				"w": wrapper{},
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			doc := map[string]interface{}{"w": wrapper{Pairs: []kv{{"a", 1}}}}
+			if len(tc.Parts) == 3 {
+				doc["w"] = wrapper{Pairs: []kv{{"a", wrapper{}}}}
+			}
+
+			p := &Pointer{
+				Parts: tc.Parts,
+				Config: Config{
+					ValueTransformationHook:        hook,
+					ValueReverseTransformationHook: reverse,
+				},
+			}
+
+			var actual interface{}
+			var err error
+			if tc.Set {
+				actual, err = p.Set(doc, tc.Value)
+			} else {
+				actual, err = p.Delete(doc)
+			}
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v != %#v", actual, tc.Output)
+			}
+		})
+	}
+}
+
+func TestPointerSet_immutable(t *testing.T) {
+	shared := map[string]interface{}{"x": 1}
+	doc := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{1, 2},
+		},
+		"shared": shared,
+	}
+
+	cases := []struct {
+		Pointer string
+		Value   interface{}
+		Output  interface{}
+	}{
+		{
+			"/a/b/0",
+			42,
+			map[string]interface{}{
+				"a":      map[string]interface{}{"b": []interface{}{42, 2}},
+				"shared": shared,
+			},
+		},
+		{
+			"/a/b/-",
+			3,
+			map[string]interface{}{
+				"a":      map[string]interface{}{"b": []interface{}{1, 2, 3}},
+				"shared": shared,
+			},
+		},
+		{
+			"/a/c",
+			"foo",
+			map[string]interface{}{
+				"a":      map[string]interface{}{"b": []interface{}{1, 2}, "c": "foo"},
+				"shared": shared,
+			},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Pointer), func(t *testing.T) {
+			p := MustParse(tc.Pointer)
+			p.Config.Immutable = true
+			actual, err := p.Set(doc, tc.Value)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v != %#v", actual, tc.Output)
+			}
+
+			// The input must be unchanged
+			expected := map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": []interface{}{1, 2},
+				},
+				"shared": shared,
+			}
+			if !reflect.DeepEqual(doc, expected) {
+				t.Fatalf("input modified: %#v", doc)
+			}
+
+			// Unaffected subtrees must be shared
+			actualShared := actual.(map[string]interface{})["shared"]
+			if reflect.ValueOf(actualShared).Pointer() != reflect.ValueOf(shared).Pointer() {
+				t.Fatal("unaffected subtree was copied")
+			}
+		})
+	}
+}
+
+func TestPointerSet_immutableStruct(t *testing.T) {
+	type inner struct {
+		Port int
+	}
+	type outer struct {
+		Inner *inner
+		Other *inner
+	}
+
+	doc := &outer{Inner: &inner{Port: 80}, Other: &inner{}}
+	p := MustParse("/Inner/Port")
+	p.Config.Immutable = true
+	actual, err := p.Set(doc, 443)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := actual.(*outer)
+	if result == doc || result.Inner == doc.Inner {
+		t.Fatal("path was not copied")
+	}
+	if result.Inner.Port != 443 || doc.Inner.Port != 80 {
+		t.Fatalf("bad: %d, %d", result.Inner.Port, doc.Inner.Port)
+	}
+	if result.Other != doc.Other {
+		t.Fatal("unaffected subtree was copied")
+	}
+}
+
+func TestPointerSet_immutableEmbeddedPointer(t *testing.T) {
+	type Creds struct {
+		User string
+	}
+	type req struct {
+		*Creds
+	}
+
+	doc := &req{Creds: &Creds{User: "bob"}}
+	p := MustParse("/User")
+	p.Config.Immutable = true
+	actual, err := p.Set(doc, "alice")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := actual.(*req)
+	if result.User != "alice" || doc.User != "bob" {
+		t.Fatalf("bad: %q, %q", result.User, doc.User)
+	}
+
+	// An unexported embedded pointer is copied as well
+	type creds Creds
+	unexported := &struct{ *creds }{&creds{User: "bob"}}
+	actual, err = p.Set(unexported, "alice")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if actual.(*struct{ *creds }).User != "alice" || unexported.User != "bob" {
+		t.Fatalf("bad: %q, %q", actual.(*struct{ *creds }).User, unexported.User)
+	}
+}
+
+func TestPointerSet_mapValues(t *testing.T) {
+	type tls struct {
+		Ports [2]int
+	}
+	type server struct {
+		Port int
+		TLS  tls
+	}
+
+	doc := map[string]interface{}{
+		"servers": map[string]server{
+			"a": {Port: 80},
+		},
+		"any": map[string]interface{}{
+			"b": server{Port: 80},
+		},
+	}
+
+	cases := []struct {
+		Pointer string
+		Value   interface{}
+	}{
+		{"/servers/a/Port", 8080},
+		{"/servers/a/TLS/Ports/1", 443},
+		{"/any/b/Port", "8081"},
+	}
+
+	for _, tc := range cases {
+		if _, err := Set(doc, tc.Pointer, tc.Value); err != nil {
+			t.Fatalf("%s: %s", tc.Pointer, err)
+		}
+	}
+
+	expected := map[string]interface{}{
+		"servers": map[string]server{
+			"a": {Port: 8080, TLS: tls{Ports: [2]int{0, 443}}},
+		},
+		"any": map[string]interface{}{
+			"b": server{Port: 8081},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Fatalf("bad: %#v", doc)
+	}
+}
+
+func TestPointerSet_array(t *testing.T) {
+	doc := &struct {
+		Ports [2]int
+	}{}
+	if _, err := Set(doc, "/Ports/1", "443"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if doc.Ports != [2]int{0, 443} {
+		t.Fatalf("bad: %#v", doc.Ports)
+	}
+
+	// Arrays can't be appended to
+	_, err := Set(doc, "/Ports/-", 80)
+	if !errors.Is(err, ErrInvalidKind) {
+		t.Fatalf("expected ErrInvalidKind, got: %v", err)
+	}
+
+	// An array that isn't addressable can't be set
+	if _, err := Set([2]int{}, "/0", 1); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// Arrays in maps are written back
+	m := map[string][2]int{"a": {1, 2}}
+	if _, err := Set(m, "/a/0", 3); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if m["a"] != [2]int{3, 2} {
+		t.Fatalf("bad: %#v", m)
+	}
+}
+
+func TestPointerSet_negativeIndex(t *testing.T) {
+	doc := []interface{}{1, 2, 3}
+	p := MustParse("/-1")
+	p.Config.NegativeIndex = true
+	if _, err := p.Set(doc, 4); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(doc, []interface{}{1, 2, 4}) {
+		t.Fatalf("bad: %#v", doc)
+	}
+
+	p = MustParse("/-4")
+	p.Config.NegativeIndex = true
+	if _, err := p.Set(doc, 4); !errors.Is(err, ErrOutOfRange) {
+		t.Fatalf("expected ErrOutOfRange, got: %v", err)
+	}
+}
@@ -2,41 +2,104 @@ package pointerstructure
 
 import (
 	"sort"
+	"strconv"
 )
 
 // Sort does an in-place sort of the pointers so that they are in order
-// of least specific to most specific alphabetized. For example:
-// "/foo", "/foo/0", "/qux"
+// of least specific to most specific alphabetized. Parts that are integers
+// are compared numerically and sort before other parts, so slice indexes
+// are in order. For example:
+// "/foo", "/foo/2", "/foo/10", "/qux"
 //
 // This ordering is ideal for applying the changes in a way that ensures
 // that parents are set first.
 func Sort(p []*Pointer) { sort.Sort(PointerSlice(p)) }
 
+// SortForDelete does an in-place sort of the pointers so that they are in
+// order of most specific to least specific, and in reverse order for
+// pointers of the same length. For example:
+// "/qux/0", "/foo/10", "/foo/2", "/foo"
+//
+// This ordering is ideal for deleting many values, since deleting a value
+// never shifts the slice index of a value that is deleted later.
+func SortForDelete(p []*Pointer) { sort.Sort(deleteOrder(p)) }
+
 // PointerSlice is a slice of pointers that adheres to sort.Interface
 type PointerSlice []*Pointer
 
 func (p PointerSlice) Len() int      { return len(p) }
 func (p PointerSlice) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
 func (p PointerSlice) Less(i, j int) bool {
-	// Equal number of parts, do a string compare per part
-	for idx, ival := range p[i].Parts {
-		// If we're passed the length of p[j] parts, then we're done
-		if idx >= len(p[j].Parts) {
+	return comparePointers(p[i], p[j]) < 0
+}
+
+// deleteOrder sorts pointers in the order described by SortForDelete.
+type deleteOrder []*Pointer
+
+func (p deleteOrder) Len() int      { return len(p) }
+func (p deleteOrder) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p deleteOrder) Less(i, j int) bool {
+	// Longest first
+	if len(p[i].Parts) != len(p[j].Parts) {
+		return len(p[i].Parts) > len(p[j].Parts)
+	}
+
+	// Equal length, reverse order
+	return comparePointers(p[i], p[j]) > 0
+}
+
+// comparePointers returns -1, 0, or 1 depending on whether a sorts before,
+// equal to, or after b in the order used by Sort.
+func comparePointers(a, b *Pointer) int {
+	// Compare per part
+	for idx, aval := range a.Parts {
+		// If we're passed the length of b parts, then we're done
+		if idx >= len(b.Parts) {
 			break
 		}
 
 		// Compare the values if they're not equal
-		jval := p[j].Parts[idx]
-		if ival != jval {
-			return ival < jval
+		if c := compareParts(aval, b.Parts[idx]); c != 0 {
+			return c
 		}
 	}
 
 	// Equal prefix, take the shorter
-	if len(p[i].Parts) != len(p[j].Parts) {
-		return len(p[i].Parts) < len(p[j].Parts)
+	switch {
+	case len(a.Parts) < len(b.Parts):
+		return -1
+	case len(a.Parts) > len(b.Parts):
+		return 1
 	}
 
 	// Equal, it doesn't matter
-	return false
+	return 0
+}
+
+// compareParts compares two pointer parts. Parts that are integers sort
+// before all other parts and are compared numerically, and the other parts
+// are compared as strings.
+func compareParts(a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	ai, aerr := strconv.ParseUint(a, 10, 64)
+	bi, berr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aerr == nil && berr != nil:
+		return -1
+	case aerr != nil && berr == nil:
+		return 1
+	case aerr == nil && ai != bi:
+		if ai < bi {
+			return -1
+		}
+		return 1
+	}
+
+	if a < b {
+		return -1
+	}
+	return 1
 }
@@ -30,6 +30,21 @@ func TestSort(t *testing.T) {
 			[]string{"/foo", "", "/bar/0"},
 			[]string{"", "/bar/0", "/foo"},
 		},
+
+		{
+			[]string{"/items/10", "/items/2", "/items/1/a", "/items"},
+			[]string{"/items", "/items/1/a", "/items/2", "/items/10"},
+		},
+
+		{
+			[]string{"/a/b", "/a/10", "/a/01", "/a/1"},
+			[]string{"/a/01", "/a/1", "/a/10", "/a/b"},
+		},
+
+		{
+			[]string{"/1a", "/10", "/2", "/b", "/01"},
+			[]string{"/01", "/2", "/10", "/1a", "/b"},
+		},
 	}
 
 	for i, tc := range cases {
@@ -52,3 +67,84 @@ func TestSort(t *testing.T) {
 		})
 	}
 }
+
+func TestSortForDelete(t *testing.T) {
+	cases := []struct {
+		Input  []string
+		Output []string
+	}{
+		{
+			[]string{"/foo", "", "/foo/0"},
+			[]string{"/foo/0", "/foo", ""},
+		},
+
+		{
+			[]string{"/items/2", "/items/10", "/items/1/a", "/qux"},
+			[]string{"/items/1/a", "/items/10", "/items/2", "/qux"},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			var ps []*Pointer
+			for _, raw := range tc.Input {
+				ps = append(ps, MustParse(raw))
+			}
+
+			SortForDelete(ps)
+
+			result := make([]string, len(ps))
+			for i, p := range ps {
+				result[i] = p.String()
+			}
+
+			if !reflect.DeepEqual(result, tc.Output) {
+				t.Fatalf("bad: %#v", result)
+			}
+		})
+	}
+}
+
+func TestSort_mixedParts(t *testing.T) {
+	input := []string{"/2", "/10", "/1a", "/a", "/02", "/1"}
+	expected := []string{"/1", "/02", "/2", "/10", "/1a", "/a"}
+
+	// The result must not depend on the input order
+	for i := range input {
+		var ps []*Pointer
+		for j := range input {
+			ps = append(ps, MustParse(input[(i+j)%len(input)]))
+		}
+
+		Sort(ps)
+
+		result := make([]string, len(ps))
+		for i, p := range ps {
+			result[i] = p.String()
+		}
+
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("bad: %#v", result)
+		}
+	}
+}
+
+func TestSortForDelete_deletes(t *testing.T) {
+	doc := []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}
+	ps := []*Pointer{MustParse("/2"), MustParse("/10"), MustParse("/3")}
+	SortForDelete(ps)
+
+	var result interface{} = doc
+	for _, p := range ps {
+		var err error
+		result, err = p.Delete(result)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	expected := []interface{}{0, 1, 4, 5, 6, 7, 8, 9, 11}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
@@ -0,0 +1,111 @@
+package pointerstructure
+
+import (
+	"errors"
+	"reflect"
+)
+
+// FuncMap returns functions for reading values by pointer in templates.
+// The result can be passed to the Funcs method of both text/template and
+// html/template templates.
+//
+// The functions are:
+//
+//	pointer VALUE POINTER
+//		Returns the value at POINTER in VALUE, like Get. A missing value
+//		is an error.
+//	hasPointer VALUE POINTER
+//		Returns true if there is a value at POINTER in VALUE.
+//	pointerDefault DEFAULT VALUE POINTER
+//		Returns the value at POINTER in VALUE, or DEFAULT if it is missing
+//		or nil.
+//	pointers VALUE POINTER
+//		Returns the pointers of the children of the value at POINTER in
+//		VALUE, such as the keys of a map.
+//
+// For example:
+//
+//	{{ pointer . "/spec/replicas" }}
+//	{{ if hasPointer . "/spec/tls" }}...{{ end }}
+//	{{ pointerDefault 1 . "/spec/replicas" }}
+//	{{ range pointers . "/spec/ports" }}{{ pointer $ . }}{{ end }}
+func FuncMap() map[string]interface{} {
+	return map[string]interface{}{
+		"pointer":        templatePointer,
+		"hasPointer":     templateHasPointer,
+		"pointerDefault": templatePointerDefault,
+		"pointers":       templatePointers,
+	}
+}
+
+func templatePointer(v interface{}, pointer string) (interface{}, error) {
+	return Get(v, pointer)
+}
+
+func templateHasPointer(v interface{}, pointer string) (bool, error) {
+	if _, err := Get(v, pointer); err != nil {
+		if isMissing(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func templatePointerDefault(def, v interface{}, pointer string) (interface{}, error) {
+	value, err := Get(v, pointer)
+	if err != nil {
+		if isMissing(err) {
+			return def, nil
+		}
+
+		return nil, err
+	}
+
+	if isNil(value) {
+		return def, nil
+	}
+
+	return value, nil
+}
+
+func templatePointers(v interface{}, pointer string) ([]string, error) {
+	p, err := Parse(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := p.childParts(v)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(parts))
+	for i, part := range parts {
+		child := &Pointer{Parts: append(p.Parts[:len(p.Parts):len(p.Parts)], part)}
+		result[i] = child.String()
+	}
+
+	return result, nil
+}
+
+// isMissing returns true if err is because a value doesn't exist.
+func isMissing(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrOutOfRange)
+}
+
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+package pointerstructure
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	type port struct {
+		Name string `pointer:"name"`
+		Port int    `pointer:"port"`
+	}
+
+	data := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"labels":   map[string]string{"tier": "web", "app": "<shop>"},
+			"ports":    []port{{"http", 80}, {"https", 443}},
+			"nothing":  nil,
+		},
+	}
+
+	cases := []struct {
+		Template string
+		Output   string
+		Err      bool
+	}{
+		{`{{ pointer . "/spec/replicas" }}`, "3", false},
+		{`{{ pointer . "/spec/ports/1/port" }}`, "443", false},
+		{`{{ pointer . "/spec/missing" }}`, "", true},
+		{`{{ pointer . "spec" }}`, "", true},
+		{`{{ hasPointer . "/spec/replicas" }}`, "true", false},
+		{`{{ hasPointer . "/spec/missing" }}`, "false", false},
+		{`{{ hasPointer . "/spec/ports/5" }}`, "false", false},
+		{`{{ pointerDefault 1 . "/spec/replicas" }}`, "3", false},
+		{`{{ pointerDefault 1 . "/spec/missing" }}`, "1", false},
+		{`{{ pointerDefault "none" . "/spec/nothing" }}`, "none", false},
+		{`{{ range pointers . "/spec/labels" }}{{ . }}={{ pointer $ . }};{{ end }}`, "/spec/labels/app=<shop>;/spec/labels/tier=web;", false},
+		{`{{ range pointers . "/spec/ports/0" }}{{ . }};{{ end }}`, "/spec/ports/0/name;/spec/ports/0/port;", false},
+		{`{{ pointers . "/spec/replicas" }}`, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Template, func(t *testing.T) {
+			tpl, err := template.New("test").Funcs(FuncMap()).Parse(tc.Template)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+
+			var buf bytes.Buffer
+			err = tpl.Execute(&buf, data)
+			if (err != nil) != tc.Err {
+				t.Fatalf("err: %s", err)
+			}
+			if err != nil {
+				return
+			}
+
+			if buf.String() != tc.Output {
+				t.Fatalf("bad: %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestFuncMap_html(t *testing.T) {
+	data := map[string]interface{}{"name": "<b>"}
+	tpl, err := htmltemplate.New("test").Funcs(FuncMap()).Parse(`<p>{{ pointer . "/name" }}</p>`)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, data); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if buf.String() != "<p>&lt;b&gt;</p>" {
+		t.Fatalf("bad: %q", buf.String())
+	}
+}